@@ -0,0 +1,38 @@
+// Package awslog adapts multilog loggers to the logging interface of the AWS SDK for Go v2, so that
+// the retries, warnings and debugging output of the SDK are written to the logs:
+//
+//	cfg, err := config.LoadDefaultConfig(ctx,
+//		config.WithLogger(awslog.New(log.Named("aws"))),
+//		config.WithClientLogMode(aws.LogRetries))
+package awslog
+
+import (
+	"github.com/aws/smithy-go/logging"
+	"github.com/crunchyroll/multilog/log"
+)
+
+// DebugVerbosity is the verbosity at which debugging output of the SDK is logged as info records.
+const DebugVerbosity = 1
+
+// Logger implements logging.Logger of the SDK with a multilog Logger.
+type Logger struct {
+	l log.Logger
+}
+
+// New returns a Logger logging through l. Records report the caller of the SDK's logger.
+func New(l log.Logger) *Logger {
+	return &Logger{l: l.Clone(log.WithCallerSkip(1))}
+}
+
+// Logf logs warnings of the SDK as warning records, debugging output as info records at
+// DebugVerbosity, and anything else as info records.
+func (a *Logger) Logf(classification logging.Classification, format string, v ...interface{}) {
+	switch classification {
+	case logging.Warn:
+		a.l.Warningf(format, v...)
+	case logging.Debug:
+		a.l.VInfof(DebugVerbosity, format, v...)
+	default:
+		a.l.Infof(format, v...)
+	}
+}
@@ -0,0 +1,9 @@
+module github.com/crunchyroll/multilog/adapter/awslog
+
+go 1.24
+
+require github.com/crunchyroll/multilog v0.0.0
+
+require github.com/aws/smithy-go v1.28.2
+
+replace github.com/crunchyroll/multilog => ../..
@@ -0,0 +1,86 @@
+// Package echolog replaces the loggers of the Echo web framework with multilog:
+//
+//	e := echo.New()
+//	echolog.Install(e, log.Named("echo"))
+//	e.Use(echolog.Middleware(log.Named("http")), echolog.Recovery(log.Named("http")))
+package echolog
+
+import (
+	"errors"
+	"fmt"
+	stdlog "log"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/crunchyroll/multilog/log"
+	"github.com/labstack/echo/v4"
+)
+
+// Install replaces the logger of e, used by Echo and its middleware, with a Logger logging through
+// l, and the standard logger of its HTTP servers with one logging their errors through l.
+func Install(e *echo.Echo, l log.Logger) {
+	e.Logger = New(l)
+	e.StdLogger = stdlog.New(log.NewLineWriter(l, log.ErrorLevel), "", 0)
+}
+
+// Middleware returns middleware logging a record for every request once it has been handled,
+// replacing Echo's Logger middleware. Server errors are logged as errors, client errors as
+// warnings, and other requests as info records.
+func Middleware(l log.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				// Let the error handler write the response, so that its status is logged.
+				c.Error(err)
+			}
+
+			req, res := c.Request(), c.Response()
+			kv := []interface{}{
+				"method", req.Method,
+				"path", req.URL.Path,
+				"status", res.Status,
+				"latency", time.Since(start),
+				"client_ip", c.RealIP(),
+				"size", res.Size,
+			}
+			if err != nil {
+				kv = append(kv, "error", err)
+			}
+			switch {
+			case res.Status >= http.StatusInternalServerError:
+				l.Errorw("request", kv...)
+			case res.Status >= http.StatusBadRequest:
+				l.Warningw("request", kv...)
+			default:
+				l.Infow("request", kv...)
+			}
+			return nil
+		}
+	}
+}
+
+// Recovery returns middleware recovering from panics in handlers, replacing Echo's Recover
+// middleware. Panics are logged as errors with the stack of the handler, and passed to Echo's error
+// handler. http.ErrAbortHandler is passed on, so that the server aborts the response.
+func Recovery(l log.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			defer func() {
+				r := recover()
+				if r == nil {
+					return
+				}
+				if rerr, ok := r.(error); ok && errors.Is(rerr, http.ErrAbortHandler) {
+					panic(r)
+				}
+				req := c.Request()
+				l.Errorf("panic handling %s %s: %v\n%s", req.Method, req.URL.Path, r, debug.Stack())
+				c.Error(fmt.Errorf("panic: %v", r))
+			}()
+			return next(c)
+		}
+	}
+}
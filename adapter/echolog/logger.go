@@ -0,0 +1,201 @@
+package echolog
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync/atomic"
+
+	"github.com/crunchyroll/multilog/log"
+	"github.com/labstack/echo/v4"
+	gommon "github.com/labstack/gommon/log"
+)
+
+var _ echo.Logger = (*Logger)(nil)
+
+// DebugVerbosity is the verbosity at which debug records of Echo are logged as info records.
+const DebugVerbosity = 1
+
+// Logger implements echo.Logger with a multilog Logger. The JSON objects passed to its methods
+// ending in j are logged as fields of a record without a message. Its output, prefix and header
+// can't be changed, since they are those of the multilog logger.
+type Logger struct {
+	l     log.Logger
+	debug log.Logger
+	// gommon.Lvl of the lowest level that is logged, read by request goroutines as it changes.
+	level  atomic.Uint32
+	output io.Writer
+}
+
+// New returns a Logger logging through l. Records report the caller of the Logger.
+func New(l log.Logger) *Logger {
+	a := &Logger{
+		l:      l.Clone(log.WithCallerSkip(1)),
+		debug:  l.Clone(log.WithCallerSkip(1), log.WithDefaultVerbosity(DebugVerbosity)),
+		output: log.NewLineWriter(l, log.InfoLevel),
+	}
+	a.SetLevel(gommon.DEBUG)
+	return a
+}
+
+// Output returns a writer logging each line written to it as an info record.
+func (a *Logger) Output() io.Writer { return a.output }
+
+// SetOutput does nothing.
+func (a *Logger) SetOutput(w io.Writer) {}
+
+// Prefix returns the empty string.
+func (a *Logger) Prefix() string { return "" }
+
+// SetPrefix does nothing.
+func (a *Logger) SetPrefix(p string) {}
+
+// Level returns the lowest level that is logged.
+func (a *Logger) Level() gommon.Lvl { return gommon.Lvl(a.level.Load()) }
+
+// SetLevel sets the lowest level that is logged. Records at levels that are logged are still
+// subject to the verbosity of the multilog logger.
+func (a *Logger) SetLevel(v gommon.Lvl) { a.level.Store(uint32(v)) }
+
+// SetHeader does nothing.
+func (a *Logger) SetHeader(h string) {}
+
+// Print logs an info record, whatever the level of the Logger.
+func (a *Logger) Print(i ...interface{}) { a.l.Info(i...) }
+
+// Printf logs an info record, whatever the level of the Logger.
+func (a *Logger) Printf(format string, args ...interface{}) { a.l.Infof(format, args...) }
+
+// Printj logs an info record, whatever the level of the Logger.
+func (a *Logger) Printj(j gommon.JSON) { a.l.Infow("", keysAndValues(j)...) }
+
+// Debug logs an info record at DebugVerbosity.
+func (a *Logger) Debug(i ...interface{}) {
+	if a.enabled(gommon.DEBUG) {
+		a.debug.Info(i...)
+	}
+}
+
+// Debugf logs an info record at DebugVerbosity.
+func (a *Logger) Debugf(format string, args ...interface{}) {
+	if a.enabled(gommon.DEBUG) {
+		a.debug.Infof(format, args...)
+	}
+}
+
+// Debugj logs an info record at DebugVerbosity.
+func (a *Logger) Debugj(j gommon.JSON) {
+	if a.enabled(gommon.DEBUG) {
+		a.debug.Infow("", keysAndValues(j)...)
+	}
+}
+
+// Info logs an info record.
+func (a *Logger) Info(i ...interface{}) {
+	if a.enabled(gommon.INFO) {
+		a.l.Info(i...)
+	}
+}
+
+// Infof logs an info record.
+func (a *Logger) Infof(format string, args ...interface{}) {
+	if a.enabled(gommon.INFO) {
+		a.l.Infof(format, args...)
+	}
+}
+
+// Infoj logs an info record.
+func (a *Logger) Infoj(j gommon.JSON) {
+	if a.enabled(gommon.INFO) {
+		a.l.Infow("", keysAndValues(j)...)
+	}
+}
+
+// Warn logs a warning record.
+func (a *Logger) Warn(i ...interface{}) {
+	if a.enabled(gommon.WARN) {
+		a.l.Warning(i...)
+	}
+}
+
+// Warnf logs a warning record.
+func (a *Logger) Warnf(format string, args ...interface{}) {
+	if a.enabled(gommon.WARN) {
+		a.l.Warningf(format, args...)
+	}
+}
+
+// Warnj logs a warning record.
+func (a *Logger) Warnj(j gommon.JSON) {
+	if a.enabled(gommon.WARN) {
+		a.l.Warningw("", keysAndValues(j)...)
+	}
+}
+
+// Error logs an error record.
+func (a *Logger) Error(i ...interface{}) {
+	if a.enabled(gommon.ERROR) {
+		a.l.Error(i...)
+	}
+}
+
+// Errorf logs an error record.
+func (a *Logger) Errorf(format string, args ...interface{}) {
+	if a.enabled(gommon.ERROR) {
+		a.l.Errorf(format, args...)
+	}
+}
+
+// Errorj logs an error record.
+func (a *Logger) Errorj(j gommon.JSON) {
+	if a.enabled(gommon.ERROR) {
+		a.l.Errorw("", keysAndValues(j)...)
+	}
+}
+
+// Fatal logs a fatal record.
+func (a *Logger) Fatal(i ...interface{}) { a.l.Fatal(i...) }
+
+// Fatalf logs a fatal record.
+func (a *Logger) Fatalf(format string, args ...interface{}) { a.l.Fatalf(format, args...) }
+
+// Fatalj logs a fatal record.
+func (a *Logger) Fatalj(j gommon.JSON) { a.l.Fatalw("", keysAndValues(j)...) }
+
+// Panic logs an error record and panics with its message.
+func (a *Logger) Panic(i ...interface{}) {
+	a.l.Error(i...)
+	panic(fmt.Sprint(i...))
+}
+
+// Panicf logs an error record and panics with its message.
+func (a *Logger) Panicf(format string, args ...interface{}) {
+	a.l.Errorf(format, args...)
+	panic(fmt.Sprintf(format, args...))
+}
+
+// Panicj logs an error record and panics with j.
+func (a *Logger) Panicj(j gommon.JSON) {
+	a.l.Errorw("", keysAndValues(j)...)
+	panic(j)
+}
+
+// enabled reports whether records at level are logged.
+func (a *Logger) enabled(level gommon.Lvl) bool {
+	return level >= a.Level()
+}
+
+// keysAndValues returns j as key-value pairs sorted by key.
+func keysAndValues(j gommon.JSON) []interface{} {
+	keys := make([]string, 0, len(j))
+	for k := range j {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kv := make([]interface{}, 0, 2*len(keys))
+	for _, k := range keys {
+		kv = append(kv, k, j[k])
+	}
+	return kv
+}
@@ -0,0 +1,76 @@
+// Package ginlog replaces the loggers of the Gin web framework with multilog:
+//
+//	ginlog.Install(log.Named("gin"))
+//	r := gin.New()
+//	r.Use(ginlog.Middleware(log.Named("http")), ginlog.Recovery(log.Named("http")))
+package ginlog
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/crunchyroll/multilog/log"
+	"github.com/gin-gonic/gin"
+)
+
+// Install routes the output Gin writes on its own, such as debug messages and route listings, to
+// l: output written to gin.DefaultWriter as info records, and to gin.DefaultErrorWriter as error
+// records. It must be called before creating engines, which capture the writers.
+func Install(l log.Logger) {
+	gin.DefaultWriter = log.NewLineWriter(l, log.InfoLevel)
+	gin.DefaultErrorWriter = log.NewLineWriter(l, log.ErrorLevel)
+}
+
+// Middleware returns middleware logging a record for every request once it has been handled,
+// replacing gin.Logger. Server errors are logged as errors, client errors as warnings, and other
+// requests as info records.
+func Middleware(l log.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		kv := []interface{}{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", status,
+			"latency", time.Since(start),
+			"client_ip", c.ClientIP(),
+			"size", c.Writer.Size(),
+		}
+		if len(c.Errors) > 0 {
+			kv = append(kv, "errors", c.Errors.String())
+		}
+		switch {
+		case status >= http.StatusInternalServerError:
+			l.Errorw("request", kv...)
+		case status >= http.StatusBadRequest:
+			l.Warningw("request", kv...)
+		default:
+			l.Infow("request", kv...)
+		}
+	}
+}
+
+// Recovery returns middleware recovering from panics in handlers, replacing gin.Recovery. Panics
+// are logged as errors with the stack of the handler, and answered with an internal server error.
+// http.ErrAbortHandler is passed on, so that the server aborts the response.
+func Recovery(l log.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			if err, ok := r.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(r)
+			}
+			l.Errorf("panic handling %s %s: %v\n%s", c.Request.Method, c.Request.URL.Path, r,
+				debug.Stack())
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}()
+		c.Next()
+	}
+}
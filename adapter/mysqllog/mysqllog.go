@@ -0,0 +1,27 @@
+// Package mysqllog adapts multilog loggers to the Logger interface of
+// github.com/go-sql-driver/mysql, which the driver uses to report connection errors:
+//
+//	mysql.SetLogger(mysqllog.New(log.Named("mysql")))
+package mysqllog
+
+import (
+	"fmt"
+
+	"github.com/crunchyroll/multilog/log"
+)
+
+// Logger implements mysql.Logger with a multilog Logger.
+type Logger struct {
+	l log.Logger
+}
+
+// New returns a Logger logging through l. Records report the caller of the driver's logger.
+func New(l log.Logger) *Logger {
+	return &Logger{l: l.Clone(log.WithCallerSkip(1))}
+}
+
+// Print logs an error record. The driver only logs errors, such as broken connections, so that
+// they aren't lost when they can't be returned to the application.
+func (a *Logger) Print(v ...interface{}) {
+	a.l.Error(fmt.Sprint(v...))
+}
@@ -0,0 +1,25 @@
+package mysqllog
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/crunchyroll/multilog/log"
+)
+
+func TestPrintReportsCaller(t *testing.T) {
+	var buf bytes.Buffer
+	l := log.NewLogger(false, false, false, &buf).With(log.F("db", "orders"))
+	a := New(l.Named("mysql"))
+
+	_, _, line, _ := runtime.Caller(0)
+	a.Print("connection reset")
+
+	want := fmt.Sprintf("mysqllog_test.go:%d: connection reset", line+1)
+	if got := buf.String(); !strings.Contains(got, want) {
+		t.Errorf("record %q doesn't report the driver's call site %q", got, want)
+	}
+}
@@ -0,0 +1,13 @@
+module github.com/crunchyroll/multilog/adapter/otelmetric
+
+go 1.25.0
+
+require (
+	github.com/crunchyroll/multilog v0.0.0
+	go.opentelemetry.io/otel v1.46.0
+	go.opentelemetry.io/otel/metric v1.46.0
+)
+
+require github.com/cespare/xxhash/v2 v2.3.0 // indirect
+
+replace github.com/crunchyroll/multilog => ../..
@@ -0,0 +1,114 @@
+// Package otelmetric reports the health of the multilog logging pipeline as OpenTelemetry metrics,
+// so that it can be monitored in the same backend as the rest of the program:
+//
+//	if err := otelmetric.Register(otel.GetMeterProvider()); err != nil {
+//		log.Warningf("unable to instrument logging: %v", err)
+//	}
+//
+// The metrics are:
+//
+//   - multilog.records: records written, by level
+//   - multilog.encode.duration: time taken to format and encode records, by level
+//   - multilog.write.errors: failed writes to destinations
+//   - multilog.dropped: records dropped, by reason; see log.Telemetry
+//   - multilog.queue.depth: records waiting in the queues of asynchronous and network writers
+package otelmetric
+
+import (
+	"context"
+	"time"
+
+	"github.com/crunchyroll/multilog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ScopeName is the name of the instrumentation scope of the metrics.
+const ScopeName = "github.com/crunchyroll/multilog"
+
+// levels are the levels records are counted by.
+var levels = []log.Level{log.InfoLevel, log.WarningLevel, log.ErrorLevel, log.FatalLevel, log.AuditLevel}
+
+// Telemetry implements log.Telemetry with OpenTelemetry instruments.
+type Telemetry struct {
+	records     metric.Int64Counter
+	encode      metric.Float64Histogram
+	writeErrors metric.Int64Counter
+	dropped     metric.Int64Counter
+
+	// attributes of measurements of each level.
+	levelAttrs map[log.Level]metric.MeasurementOption
+}
+
+// New returns a Telemetry creating its instruments with the meters of mp.
+func New(mp metric.MeterProvider) (*Telemetry, error) {
+	meter := mp.Meter(ScopeName)
+	t := &Telemetry{levelAttrs: map[log.Level]metric.MeasurementOption{}}
+	for _, level := range levels {
+		t.levelAttrs[level] = metric.WithAttributeSet(attribute.NewSet(attribute.String("level", level.String())))
+	}
+
+	var err error
+	if t.records, err = meter.Int64Counter("multilog.records",
+		metric.WithDescription("Records written."), metric.WithUnit("{record}")); err != nil {
+		return nil, err
+	}
+	if t.encode, err = meter.Float64Histogram("multilog.encode.duration",
+		metric.WithDescription("Time taken to format and encode records."), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if t.writeErrors, err = meter.Int64Counter("multilog.write.errors",
+		metric.WithDescription("Failed writes to log destinations."), metric.WithUnit("{error}")); err != nil {
+		return nil, err
+	}
+	if t.dropped, err = meter.Int64Counter("multilog.dropped",
+		metric.WithDescription("Records dropped."), metric.WithUnit("{record}")); err != nil {
+		return nil, err
+	}
+	if _, err = meter.Int64ObservableGauge("multilog.queue.depth",
+		metric.WithDescription("Records waiting in the queues of asynchronous and network writers."),
+		metric.WithUnit("{record}"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			o.Observe(int64(log.QueueDepth()))
+			return nil
+		})); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Register creates a Telemetry with the meters of mp and makes it receive the measurements of the
+// logging pipeline with log.SetTelemetry.
+func Register(mp metric.MeterProvider) error {
+	t, err := New(mp)
+	if err != nil {
+		return err
+	}
+	log.SetTelemetry(t)
+	return nil
+}
+
+// attrs returns the attributes of measurements of level.
+func (t *Telemetry) attrs(level log.Level) metric.MeasurementOption {
+	if a, ok := t.levelAttrs[level]; ok {
+		return a
+	}
+	return metric.WithAttributes(attribute.String("level", level.String()))
+}
+
+// Record implements the log.Telemetry interface.
+func (t *Telemetry) Record(level log.Level, encode time.Duration) {
+	attrs := t.attrs(level)
+	t.records.Add(context.Background(), 1, attrs)
+	t.encode.Record(context.Background(), encode.Seconds(), attrs)
+}
+
+// WriteError implements the log.Telemetry interface.
+func (t *Telemetry) WriteError(err error) {
+	t.writeErrors.Add(context.Background(), 1)
+}
+
+// Dropped implements the log.Telemetry interface.
+func (t *Telemetry) Dropped(reason string, n int64) {
+	t.dropped.Add(context.Background(), n, metric.WithAttributes(attribute.String("reason", reason)))
+}
@@ -0,0 +1,80 @@
+// Package pgxlog adapts multilog loggers to the Logger interface of the tracelog package of
+// github.com/jackc/pgx/v5, which traces queries, connections and errors:
+//
+//	cfg.ConnConfig.Tracer = &tracelog.TraceLog{
+//		Logger:   pgxlog.New(log.Named("pgx"), 500*time.Millisecond),
+//		LogLevel: tracelog.LogLevelInfo,
+//	}
+package pgxlog
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/crunchyroll/multilog/log"
+	"github.com/jackc/pgx/v5/tracelog"
+)
+
+// DebugVerbosity is the verbosity at which debug records of pgx are logged as info records. Trace
+// records are logged at the next verbosity.
+const DebugVerbosity = 1
+
+// Logger implements tracelog.Logger with a multilog Logger. The data of each record is logged as
+// fields.
+type Logger struct {
+	l         log.Logger
+	debug     log.Logger
+	trace     log.Logger
+	slowQuery time.Duration
+}
+
+// New returns a Logger logging through l. Records of queries that took at least slowQuery are
+// logged as warnings; a slowQuery of zero disables this. Records report the caller of the tracer.
+func New(l log.Logger, slowQuery time.Duration) *Logger {
+	return &Logger{
+		l:         l.Clone(log.WithCallerSkip(1)),
+		debug:     l.Clone(log.WithCallerSkip(1), log.WithDefaultVerbosity(DebugVerbosity)),
+		trace:     l.Clone(log.WithCallerSkip(1), log.WithDefaultVerbosity(DebugVerbosity+1)),
+		slowQuery: slowQuery,
+	}
+}
+
+// Log logs msg at the multilog level closest to level, with data as fields.
+func (a *Logger) Log(ctx context.Context, level tracelog.LogLevel, msg string,
+	data map[string]interface{}) {
+	kv := keysAndValues(data)
+	switch {
+	case level == tracelog.LogLevelError:
+		a.l.Errorw(msg, kv...)
+	case level == tracelog.LogLevelWarn || a.slow(data):
+		a.l.Warningw(msg, kv...)
+	case level == tracelog.LogLevelInfo:
+		a.l.Infow(msg, kv...)
+	case level == tracelog.LogLevelDebug:
+		a.debug.Infow(msg, kv...)
+	default:
+		a.trace.Infow(msg, kv...)
+	}
+}
+
+// slow reports whether data is that of a query that took at least slowQuery.
+func (a *Logger) slow(data map[string]interface{}) bool {
+	d, ok := data["time"].(time.Duration)
+	return ok && a.slowQuery > 0 && d >= a.slowQuery
+}
+
+// keysAndValues returns data as key-value pairs sorted by key.
+func keysAndValues(data map[string]interface{}) []interface{} {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kv := make([]interface{}, 0, 2*len(keys))
+	for _, k := range keys {
+		kv = append(kv, k, data[k])
+	}
+	return kv
+}
@@ -0,0 +1,7 @@
+module github.com/crunchyroll/multilog/adapter/retrylog
+
+go 1.23
+
+require github.com/crunchyroll/multilog v0.0.0
+
+replace github.com/crunchyroll/multilog => ../..
@@ -0,0 +1,47 @@
+// Package retrylog adapts multilog loggers to the LeveledLogger interface of
+// github.com/hashicorp/go-retryablehttp, so that its retries are logged with their structured
+// fields:
+//
+//	client := retryablehttp.NewClient()
+//	client.Logger = retrylog.New(log.Named("http"))
+package retrylog
+
+import "github.com/crunchyroll/multilog/log"
+
+// DebugVerbosity is the verbosity at which debug records of the client are logged as info records.
+const DebugVerbosity = 1
+
+// Logger implements retryablehttp.LeveledLogger with a multilog Logger. The key-value pairs passed
+// with each message are logged as fields.
+type Logger struct {
+	l     log.Logger
+	debug log.Logger
+}
+
+// New returns a Logger logging through l. Records report the caller of the client's logger.
+func New(l log.Logger) *Logger {
+	return &Logger{
+		l:     l.Clone(log.WithCallerSkip(1)),
+		debug: l.Clone(log.WithCallerSkip(1), log.WithDefaultVerbosity(DebugVerbosity)),
+	}
+}
+
+// Error logs an error record.
+func (a *Logger) Error(msg string, keysAndValues ...interface{}) {
+	a.l.Errorw(msg, keysAndValues...)
+}
+
+// Warn logs a warning record.
+func (a *Logger) Warn(msg string, keysAndValues ...interface{}) {
+	a.l.Warningw(msg, keysAndValues...)
+}
+
+// Info logs an info record.
+func (a *Logger) Info(msg string, keysAndValues ...interface{}) {
+	a.l.Infow(msg, keysAndValues...)
+}
+
+// Debug logs an info record at DebugVerbosity.
+func (a *Logger) Debug(msg string, keysAndValues ...interface{}) {
+	a.debug.Infow(msg, keysAndValues...)
+}
@@ -0,0 +1,216 @@
+// Command multilog tails, filters and pretty-prints the logs written by package log, in either the
+// text format or FormatOTel.
+//
+// Usage:
+//
+//	multilog [flags] [file ...]
+//	multilog merge [-names] file ...
+//	multilog tui [flags] [file | url ...]
+//
+// Without files, multilog reads standard input. Records are filtered by level, caller, component
+// and fields; verbosity isn't recorded in logs, so it can only be filtered when writing them.
+// Continuation lines of multi-line records are shown with their record.
+//
+// The merge subcommand merges text logs written with timestamps, such as the per-process files
+// created by log.Init, into a single chronologically ordered stream.
+//
+// The tui subcommand follows logs interactively in the terminal, with keys to filter records by
+// level, pause, search and choose the columns shown. Besides files, it follows the records
+// streamed live by log.Dashboard handlers, given by their URLs.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/crunchyroll/multilog/log"
+)
+
+// fieldFlags collects the repeated -field flags.
+type fieldFlags []log.Field
+
+// String implements the flag.Value interface.
+func (f *fieldFlags) String() string {
+	var s []string
+	for _, field := range *f {
+		s = append(s, field.String())
+	}
+	return strings.Join(s, ",")
+}
+
+// Set implements the flag.Value interface.
+func (f *fieldFlags) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("field filter %q is not of the form key=value", s)
+	}
+	*f = append(*f, log.F(key, value))
+	return nil
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "merge" {
+		mergeCommand(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "tui" {
+		tuiCommand(os.Args[2:])
+		return
+	}
+
+	var (
+		f        filter
+		follow   = flag.Bool("f", false, "keep reading as the files grow, like tail -f")
+		last     = flag.Int("n", 0, "only show the last `n` matching records of each file before following; 0 shows all")
+		level    = flag.String("level", "info", "minimum `level` of records to show: info, warning, error or fatal; audit shows only audit records")
+		colorFlg = flag.String("color", "auto", "colorize output: auto, always or never")
+	)
+	flag.StringVar(&f.caller, "caller", "", "only show records whose `file:line` contains this text")
+	flag.StringVar(&f.component, "component", "", "only show records of this component `path` or its children")
+	flag.Var((*fieldFlags)(&f.fields), "field", "only show records with the field `key=value`; may be repeated")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [file ...]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s merge [-names] file ...\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s tui [flags] [file | url ...]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var err error
+	if f.minLevel, f.auditOnly, err = parseLevel(*level); err != nil {
+		fatalf("%v", err)
+	}
+	p := printer{w: bufio.NewWriter(os.Stdout)}
+	switch *colorFlg {
+	case "auto":
+		p.color = isTerminal(os.Stdout)
+	case "always":
+		p.color = true
+	case "never":
+	default:
+		fatalf("invalid -color %q", *colorFlg)
+	}
+
+	var sources []*source
+	if flag.NArg() == 0 {
+		sources = append(sources, newSource(os.Stdin, "", f))
+	}
+	for _, name := range flag.Args() {
+		file, err := os.Open(name)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		defer file.Close()
+		sources = append(sources, newSource(file, name, f))
+	}
+	showNames := len(sources) > 1
+
+	for _, s := range sources {
+		lines := s.readAvailable()
+		if !*follow {
+			lines = append(lines, s.rest()...)
+		}
+		if *last > 0 {
+			lines = lastRecords(lines, *last)
+		}
+		p.print(lines, s.name, showNames)
+	}
+	p.w.Flush()
+
+	for *follow {
+		read := false
+		for _, s := range sources {
+			if lines := s.readAvailable(); len(lines) > 0 {
+				p.print(lines, s.name, showNames)
+				read = true
+			}
+		}
+		p.w.Flush()
+		if !read {
+			time.Sleep(250 * time.Millisecond)
+		}
+	}
+}
+
+// fatalf prints an error message and exits.
+func fatalf(format string, a ...interface{}) {
+	fmt.Fprintf(os.Stderr, "multilog: "+format+"\n", a...)
+	os.Exit(1)
+}
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// source reads the lines of one log file or stream, remembering whether the current record
+// matches the filter so that its continuation lines can be shown or hidden with it.
+type source struct {
+	r       *bufio.Reader
+	name    string
+	f       filter
+	partial string
+	showing bool
+}
+
+// newSource returns a source reading r, called name in the output.
+func newSource(r io.Reader, name string, f filter) *source {
+	return &source{r: bufio.NewReader(r), name: name, f: f}
+}
+
+// readAvailable returns the lines that can be read without waiting, parsed and filtered. An
+// incomplete last line is kept until the rest of it is written.
+func (s *source) readAvailable() []line {
+	var lines []line
+	for {
+		text, err := s.r.ReadString('\n')
+		if err != nil {
+			s.partial += text
+			return lines
+		}
+		text = strings.TrimRight(s.partial+text, "\r\n")
+		s.partial = ""
+
+		l := parseLine(text)
+		if l.rec != nil {
+			s.showing = s.f.match(l.rec)
+		}
+		if s.showing {
+			lines = append(lines, l)
+		}
+	}
+}
+
+// rest returns the incomplete last line left over by readAvailable, if it matches the filter.
+func (s *source) rest() []line {
+	if s.partial == "" {
+		return nil
+	}
+	l := parseLine(strings.TrimRight(s.partial, "\r"))
+	s.partial = ""
+	if l.rec != nil {
+		s.showing = s.f.match(l.rec)
+	}
+	if !s.showing {
+		return nil
+	}
+	return []line{l}
+}
+
+// lastRecords returns the lines of the last n records in lines.
+func lastRecords(lines []line, n int) []line {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if lines[i].rec != nil {
+			if n--; n == 0 {
+				return lines[i:]
+			}
+		}
+	}
+	return lines
+}
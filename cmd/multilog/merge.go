@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/crunchyroll/multilog/log/logparse"
+)
+
+// mergeCommand runs the merge subcommand with args.
+func mergeCommand(args []string) {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	names := fs.Bool("names", false, "prefix each record with the name of the file it came from")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s merge [flags] file ...\n\n", os.Args[0])
+		fmt.Fprintln(fs.Output(), "Merges text logs written with timestamps into a single chronological stream.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var logs []io.Reader
+	for _, name := range fs.Args() {
+		f, err := os.Open(name)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		defer f.Close()
+		logs = append(logs, f)
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	m := logparse.NewMerger(logs...)
+	for {
+		rec, i, err := m.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			w.Flush()
+			fatalf("%s: %v", fs.Arg(i), err)
+		}
+		if *names {
+			fmt.Fprintf(w, "%s: ", filepath.Base(fs.Arg(i)))
+		}
+		fmt.Fprintln(w, rec.Raw)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/crunchyroll/multilog/log"
+	"github.com/crunchyroll/multilog/log/logparse"
+)
+
+// record is a record read from a text or JSON log.
+type record struct {
+	logparse.Record
+	// fields of a JSON record, sorted by key. The fields of text records are part of their message.
+	fields []log.Field
+}
+
+// line is a line read from a log. rec is nil for continuation lines and lines that couldn't be
+// parsed.
+type line struct {
+	text string
+	rec  *record
+}
+
+// levelNames maps level flag values and OTel severity texts to levels.
+var levelNames = map[string]log.Level{
+	"info":    log.InfoLevel,
+	"warning": log.WarningLevel,
+	"error":   log.ErrorLevel,
+	"fatal":   log.FatalLevel,
+	"audit":   log.AuditLevel,
+}
+
+// parseLevel parses the -level flag.
+func parseLevel(s string) (level log.Level, auditOnly bool, err error) {
+	level, ok := levelNames[strings.ToLower(s)]
+	if !ok {
+		return 0, false, fmt.Errorf("invalid level %q", s)
+	}
+	return level, level == log.AuditLevel, nil
+}
+
+// parseLine parses a line of a text or JSON log.
+func parseLine(text string) line {
+	if strings.HasPrefix(text, "{") {
+		if rec, err := parseOTel(text); err == nil {
+			return line{text: text, rec: rec}
+		}
+	}
+	if rec, err := logparse.Parse(text); err == nil {
+		return line{text: text, rec: &record{Record: rec}}
+	}
+	return line{text: text}
+}
+
+// otelRecord is the part of a FormatOTel record shown by multilog.
+type otelRecord struct {
+	Timestamp    time.Time                  `json:"timestamp"`
+	SeverityText string                     `json:"severity_text"`
+	Body         string                     `json:"body"`
+	Attributes   map[string]json.RawMessage `json:"attributes"`
+}
+
+// parseOTel parses a FormatOTel record.
+func parseOTel(text string) (*record, error) {
+	var o otelRecord
+	if err := json.Unmarshal([]byte(text), &o); err != nil {
+		return nil, err
+	}
+	level, ok := levelNames[strings.ToLower(o.SeverityText)]
+	if !ok {
+		return nil, fmt.Errorf("unknown severity %q", o.SeverityText)
+	}
+
+	rec := &record{Record: logparse.Record{Time: o.Timestamp, Level: level, Count: -1, Message: o.Body}}
+	for key, raw := range o.Attributes {
+		var v interface{}
+		json.Unmarshal(raw, &v)
+		switch key {
+		case "code.filepath":
+			rec.File, _ = v.(string)
+		case "code.lineno":
+			n, _ := v.(float64)
+			rec.Line = int(n)
+		default:
+			if key == "component" {
+				rec.Component, _ = v.(string)
+			}
+			rec.fields = append(rec.fields, log.F(key, v))
+		}
+	}
+	sort.Slice(rec.fields, func(i, j int) bool { return rec.fields[i].Key < rec.fields[j].Key })
+	return rec, nil
+}
+
+// filter selects the records to show.
+type filter struct {
+	minLevel  log.Level
+	auditOnly bool
+	caller    string
+	component string
+	fields    []log.Field
+}
+
+// match reports whether rec passes f.
+func (f filter) match(rec *record) bool {
+	switch {
+	case f.auditOnly:
+		if rec.Level != log.AuditLevel {
+			return false
+		}
+	// Audit records aren't more severe than errors, so treat them like info records.
+	case rec.Level != log.AuditLevel && rec.Level < f.minLevel,
+		rec.Level == log.AuditLevel && f.minLevel > log.InfoLevel:
+		return false
+	}
+	if f.caller != "" && !strings.Contains(fmt.Sprintf("%s:%d", rec.File, rec.Line), f.caller) {
+		return false
+	}
+	if f.component != "" && rec.Component != f.component && !strings.HasPrefix(rec.Component, f.component+".") {
+		return false
+	}
+	for _, want := range f.fields {
+		if !rec.hasField(want) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasField reports whether rec has the field want, comparing values as text.
+func (rec *record) hasField(want log.Field) bool {
+	for _, f := range rec.fields {
+		if f.Key == want.Key && fmt.Sprint(f.Value) == want.Value {
+			return true
+		}
+	}
+	// Fields of text records are part of the message, as formatted by Field.String.
+	return strings.Contains(" "+rec.Message+" ", " "+want.String()+" ")
+}
+
+// Colors used for each level, as in stderr output of package log.
+var levelColors = map[log.Level]string{
+	log.InfoLevel:    "\x1b[32m",
+	log.WarningLevel: "\x1b[33m",
+	log.ErrorLevel:   "\x1b[31m",
+	log.FatalLevel:   "\x1b[31m",
+	log.AuditLevel:   "\x1b[36m",
+}
+
+const resetColor = "\x1b[0m"
+
+// printer writes lines to w.
+type printer struct {
+	w     *bufio.Writer
+	color bool
+	// level of the last record printed, used to color its continuation lines.
+	level log.Level
+}
+
+// print writes lines read from the log called name, prefixing them with the name if showName is
+// set. Text records are written as they are, JSON records are pretty-printed like text records.
+func (p *printer) print(lines []line, name string, showName bool) {
+	for _, l := range lines {
+		if showName {
+			fmt.Fprintf(p.w, "%s: ", name)
+		}
+		text := l.text
+		if l.rec != nil {
+			p.level = l.rec.Level
+			if l.rec.fields != nil || strings.HasPrefix(text, "{") {
+				text = l.rec.pretty()
+			}
+		}
+		if p.color {
+			text = levelColors[p.level] + text + resetColor
+		}
+		p.w.WriteString(text)
+		p.w.WriteByte('\n')
+	}
+}
+
+// pretty returns rec formatted like a text record, with its fields after the message.
+func (rec *record) pretty() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s [%s", rec.Time.Local().Format("2006-01-02 15:04:05.000"), rec.Level)
+	if rec.Component != "" {
+		fmt.Fprintf(&b, " %s", rec.Component)
+	}
+	fmt.Fprintf(&b, "] %s:%d: %s", filepath.Base(rec.File), rec.Line, rec.Message)
+	for _, f := range rec.fields {
+		if f.Key == "component" {
+			continue
+		}
+		b.WriteByte(' ')
+		if s, ok := f.Value.(string); ok && strings.Contains(s, "\n") {
+			// Stack traces and the like are easier to read on lines of their own.
+			fmt.Fprintf(&b, "%s=\n%s", f.Key, s)
+			continue
+		}
+		b.WriteString(f.String())
+	}
+	return b.String()
+}
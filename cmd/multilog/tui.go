@@ -0,0 +1,508 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/crunchyroll/multilog/log"
+	"github.com/crunchyroll/multilog/log/logparse"
+)
+
+// tuiHelp describes the keys of the tui subcommand.
+const tuiHelp = `Keys:
+  i w e x   show info, warning, error or fatal records and above
+  a         show audit records only
+  space     pause or resume following
+  /         search for text; an empty search shows everything
+  t c f     toggle the time, caller and fields columns
+  1-9       toggle the columns of the fields given with -columns
+  q         quit`
+
+// tuiEntry is a record shown by the tui subcommand, or a line that isn't part of any record.
+type tuiEntry struct {
+	source string
+	rec    *record
+	text   string
+	// number of continuation lines of the record, which aren't shown.
+	extra int
+}
+
+// tuiView is the state of the screen of the tui subcommand.
+type tuiView struct {
+	out     *bufio.Writer
+	entries []tuiEntry
+	history int
+	// entries received while paused, shown once following resumes.
+	pending []tuiEntry
+	paused  bool
+
+	f         filter
+	search    string
+	searching bool
+	input     string
+
+	showTime, showCaller, showFields, showNames bool
+	columns                                     []string
+	columnOn                                    []bool
+
+	width, height int
+}
+
+// tuiCommand runs the tui subcommand with args.
+func tuiCommand(args []string) {
+	fs := flag.NewFlagSet("tui", flag.ExitOnError)
+	level := fs.String("level", "info", "initial minimum `level` of records to show")
+	columns := fs.String("columns", "", "comma-separated `keys` of fields to show in columns of their own")
+	history := fs.Int("history", 10000, "number of `records` to keep")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s tui [flags] [file | url ...]\n\n", os.Args[0])
+		fmt.Fprintln(fs.Output(), "Follows logs interactively. URLs are those of log.Dashboard handlers, whose records are")
+		fmt.Fprintln(fs.Output(), "streamed live. Without files or URLs, the log is read from standard input.")
+		fmt.Fprintln(fs.Output())
+		fmt.Fprintln(fs.Output(), tuiHelp)
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	v := &tuiView{history: *history, showTime: true, showCaller: true, showFields: true}
+	var err error
+	if v.f.minLevel, v.f.auditOnly, err = parseLevel(*level); err != nil {
+		fatalf("%v", err)
+	}
+	if *columns != "" {
+		v.columns = strings.Split(*columns, ",")
+		v.columnOn = make([]bool, len(v.columns))
+		for i := range v.columnOn {
+			v.columnOn[i] = true
+		}
+	}
+
+	entries := make(chan tuiEntry, 256)
+	names := fs.Args()
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+	v.showNames = len(names) > 1
+	for _, name := range names {
+		switch {
+		case name == "-":
+			go followReader(os.Stdin, "stdin", entries)
+		case strings.HasPrefix(name, "http://"), strings.HasPrefix(name, "https://"):
+			go followStream(name, entries)
+		default:
+			file, err := os.Open(name)
+			if err != nil {
+				fatalf("%v", err)
+			}
+			defer file.Close()
+			go followReader(file, filepath.Base(name), entries)
+		}
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		fatalf("tui needs a terminal: %v", err)
+	}
+	defer tty.Close()
+	restore, err := rawMode(tty)
+	if err != nil {
+		fatalf("tui needs a terminal: %v", err)
+	}
+	v.out = bufio.NewWriter(tty)
+	// Switch to the alternate screen so that the shell's screen is back on exit.
+	v.out.WriteString("\x1b[?1049h\x1b[?25l")
+	quit := func() {
+		v.out.WriteString("\x1b[?25h\x1b[?1049l")
+		v.out.Flush()
+		restore()
+	}
+	defer quit()
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	keys := make(chan byte)
+	go func() {
+		r := bufio.NewReader(tty)
+		for {
+			b, err := r.ReadByte()
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- b
+		}
+	}()
+
+	v.width, v.height = terminalSize(tty)
+	resize := time.NewTicker(time.Second)
+	defer resize.Stop()
+	redraw := time.NewTicker(100 * time.Millisecond)
+	defer redraw.Stop()
+	dirty := true
+	for {
+		select {
+		case e := <-entries:
+			v.add(e)
+			dirty = true
+		case b, ok := <-keys:
+			if !ok || !v.key(b) {
+				return
+			}
+			dirty = true
+		case <-interrupts:
+			return
+		case <-resize.C:
+			if w, h := terminalSize(tty); w != v.width || h != v.height {
+				v.width, v.height = w, h
+				dirty = true
+			}
+		case <-redraw.C:
+			if dirty {
+				v.draw()
+				dirty = false
+			}
+		}
+	}
+}
+
+// rawMode puts the terminal tty in a mode where keys are read as they are typed, without being
+// echoed, and returns a function restoring its previous mode.
+func rawMode(tty *os.File) (func(), error) {
+	saved, err := stty(tty, "-g")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := stty(tty, "-icanon", "-echo", "min", "1"); err != nil {
+		return nil, err
+	}
+	return func() { stty(tty, saved) }, nil
+}
+
+// terminalSize returns the width and height of the terminal tty, or a common default if it can't
+// be determined.
+func terminalSize(tty *os.File) (int, int) {
+	out, err := stty(tty, "size")
+	if err == nil {
+		if rows, cols, ok := strings.Cut(out, " "); ok {
+			h, herr := strconv.Atoi(rows)
+			w, werr := strconv.Atoi(cols)
+			if herr == nil && werr == nil && w > 0 && h > 0 {
+				return w, h
+			}
+		}
+	}
+	return 80, 24
+}
+
+// stty runs stty with args on the terminal tty and returns its output.
+func stty(tty *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = tty
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// followReader sends the records read from r, called name, to entries, following it as it grows.
+func followReader(r io.Reader, name string, entries chan<- tuiEntry) {
+	s := newSource(r, name, filter{})
+	var e *tuiEntry
+	for {
+		lines := s.readAvailable()
+		for _, l := range lines {
+			if l.rec == nil && e != nil {
+				e.extra++
+				continue
+			}
+			if e != nil {
+				entries <- *e
+			}
+			e = &tuiEntry{source: name, rec: l.rec, text: l.text}
+		}
+		// A record is complete once the next one starts, or once nothing more has been written.
+		if len(lines) == 0 && e != nil {
+			entries <- *e
+			e = nil
+		}
+		if len(lines) == 0 {
+			time.Sleep(250 * time.Millisecond)
+		}
+	}
+}
+
+// followStream sends the records streamed by the log.Dashboard at url to entries, reconnecting
+// when the stream ends.
+func followStream(url string, entries chan<- tuiEntry) {
+	for {
+		err := readStream(url, entries)
+		if err == nil {
+			err = io.EOF
+		}
+		entries <- tuiEntry{source: url, text: fmt.Sprintf("stream interrupted: %v; reconnecting", err)}
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// readStream sends the records of a single server-sent event stream from url to entries.
+func readStream(url string, entries chan<- tuiEntry) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s", resp.Status)
+	}
+
+	sc := bufio.NewScanner(resp.Body)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		data, ok := strings.CutPrefix(sc.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var r log.Record
+		if err := json.Unmarshal([]byte(data), &r); err != nil {
+			if err := r.UnmarshalText([]byte(data)); err != nil {
+				entries <- tuiEntry{source: url, text: data}
+				continue
+			}
+		}
+		entries <- tuiEntry{source: url, rec: streamRecord(r), text: data}
+	}
+	return sc.Err()
+}
+
+// streamRecord converts a record received from a log.Dashboard stream.
+func streamRecord(r log.Record) *record {
+	rec := &record{Record: logparse.Record{Time: r.Time, Level: r.Level, Count: r.Seq, File: r.File,
+		Line: r.Line, Message: r.Message}, fields: r.Fields}
+	for _, f := range r.Fields {
+		if f.Key == "component" {
+			rec.Component = fmt.Sprint(f.Value)
+		}
+	}
+	if rec.fields == nil {
+		// Mark the record as structured, so that it is shown with its columns.
+		rec.fields = []log.Field{}
+	}
+	return rec
+}
+
+// add adds e to the entries of v, or to its pending entries while v is paused.
+func (v *tuiView) add(e tuiEntry) {
+	if v.paused {
+		v.pending = append(v.pending, e)
+		return
+	}
+	v.entries = append(v.entries, e)
+	if len(v.entries) > v.history {
+		v.entries = append(v.entries[:0], v.entries[len(v.entries)-v.history:]...)
+	}
+}
+
+// key handles the key b, returning false if it quits.
+func (v *tuiView) key(b byte) bool {
+	if v.searching {
+		switch b {
+		case '\r', '\n':
+			v.search, v.searching = v.input, false
+		case 0x1b:
+			v.searching = false
+		case 0x7f, '\b':
+			if v.input != "" {
+				_, size := utf8.DecodeLastRuneInString(v.input)
+				v.input = v.input[:len(v.input)-size]
+			}
+		default:
+			if b >= 0x20 {
+				v.input += string([]byte{b})
+			}
+		}
+		return true
+	}
+
+	switch b {
+	case 'q', 0x03:
+		return false
+	case 'i':
+		v.f.minLevel, v.f.auditOnly = log.InfoLevel, false
+	case 'w':
+		v.f.minLevel, v.f.auditOnly = log.WarningLevel, false
+	case 'e':
+		v.f.minLevel, v.f.auditOnly = log.ErrorLevel, false
+	case 'x':
+		v.f.minLevel, v.f.auditOnly = log.FatalLevel, false
+	case 'a':
+		v.f.minLevel, v.f.auditOnly = log.AuditLevel, true
+	case ' ':
+		v.paused = !v.paused
+		if !v.paused {
+			pending := v.pending
+			v.pending = nil
+			for _, e := range pending {
+				v.add(e)
+			}
+		}
+	case '/':
+		v.searching, v.input = true, ""
+	case 't':
+		v.showTime = !v.showTime
+	case 'c':
+		v.showCaller = !v.showCaller
+	case 'f':
+		v.showFields = !v.showFields
+	default:
+		if i := int(b - '1'); b >= '1' && b <= '9' && i < len(v.columns) {
+			v.columnOn[i] = !v.columnOn[i]
+		}
+	}
+	return true
+}
+
+// visible reports whether e passes the level filter and search of v.
+func (v *tuiView) visible(e tuiEntry) bool {
+	if e.rec != nil && !v.f.match(e.rec) {
+		return false
+	}
+	if e.rec == nil && (v.f.auditOnly || v.f.minLevel > log.InfoLevel) {
+		return false
+	}
+	return v.search == "" || strings.Contains(strings.ToLower(e.text), strings.ToLower(v.search))
+}
+
+// draw redraws the screen.
+func (v *tuiView) draw() {
+	rows := v.height - 1
+	var shown []string
+	for i := len(v.entries) - 1; i >= 0 && len(shown) < rows; i-- {
+		if e := v.entries[i]; v.visible(e) {
+			shown = append(shown, v.format(e))
+		}
+	}
+
+	// Overwrite the previous screen rather than clearing it first, which would flicker.
+	v.out.WriteString("\x1b[H")
+	for i := len(shown) - 1; i >= 0; i-- {
+		v.out.WriteString(shown[i])
+		v.out.WriteString(resetColor + "\x1b[K\r\n")
+	}
+	v.out.WriteString("\x1b[J")
+	fmt.Fprintf(v.out, "\x1b[%d;1H\x1b[7m%s\x1b[0m", v.height, clip(v.status(), v.width))
+	v.out.Flush()
+}
+
+// format returns the line showing e, clipped to the width of the screen.
+func (v *tuiView) format(e tuiEntry) string {
+	var b strings.Builder
+	if v.showNames {
+		fmt.Fprintf(&b, "%s: ", e.source)
+	}
+	rec := e.rec
+	if rec == nil {
+		b.WriteString(e.text)
+		return clip(b.String(), v.width)
+	}
+
+	if v.showTime && !rec.Time.IsZero() {
+		fmt.Fprintf(&b, "%s ", rec.Time.Local().Format("15:04:05.000"))
+	}
+	fmt.Fprintf(&b, "%-7s ", rec.Level)
+	if rec.Component != "" {
+		fmt.Fprintf(&b, "[%s] ", rec.Component)
+	}
+	if v.showCaller {
+		fmt.Fprintf(&b, "%s:%d: ", filepath.Base(rec.File), rec.Line)
+	}
+	for i, key := range v.columns {
+		if !v.columnOn[i] {
+			continue
+		}
+		value := "-"
+		for _, f := range rec.fields {
+			if f.Key == key {
+				value = fmt.Sprint(f.Value)
+			}
+		}
+		fmt.Fprintf(&b, "%s=%s ", key, value)
+	}
+	msg, _, _ := strings.Cut(rec.Message, "\n")
+	b.WriteString(msg)
+	if v.showFields {
+		for _, f := range rec.fields {
+			if f.Key != "component" && !v.isColumn(f.Key) {
+				b.WriteByte(' ')
+				b.WriteString(strings.ReplaceAll(f.String(), "\n", " "))
+			}
+		}
+	}
+	if n := e.extra + strings.Count(rec.Message, "\n"); n > 0 {
+		fmt.Fprintf(&b, " (+%d lines)", n)
+	}
+	return levelColors[rec.Level] + clip(b.String(), v.width)
+}
+
+// isColumn reports whether the field key is shown in a column of its own.
+func (v *tuiView) isColumn(key string) bool {
+	for i, c := range v.columns {
+		if c == key && v.columnOn[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// status returns the text of the status line.
+func (v *tuiView) status() string {
+	if v.searching {
+		return "search: " + v.input
+	}
+	level := strings.ToLower(v.f.minLevel.String()) + "+"
+	if v.f.auditOnly {
+		level = "audit"
+	}
+	s := fmt.Sprintf(" level %s", level)
+	if v.search != "" {
+		s += fmt.Sprintf(" | search %q", v.search)
+	}
+	if v.paused {
+		s += fmt.Sprintf(" | PAUSED, %d new", len(v.pending))
+	} else {
+		s += " | following"
+	}
+	s += fmt.Sprintf(" | %d records | i/w/e/x/a level, space pause, / search, t/c/f", len(v.entries))
+	if len(v.columns) > 0 {
+		s += fmt.Sprintf(", 1-%d", len(v.columns))
+	}
+	s += " columns, q quit "
+	return s + strings.Repeat(" ", max(v.width-utf8.RuneCountInString(s), 0))
+}
+
+// clip shortens s to at most width characters.
+func clip(s string, width int) string {
+	n := 0
+	for i := range s {
+		if n == width {
+			return s[:i]
+		}
+		n++
+	}
+	return s
+}
@@ -0,0 +1,3 @@
+module github.com/crunchyroll/multilog
+
+go 1.23
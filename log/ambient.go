@@ -0,0 +1,84 @@
+package log
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+)
+
+// ambient holds the fields pushed by each goroutine with PushFields, keyed by goroutine ID.
+var ambient struct {
+	mu     sync.Mutex
+	fields map[uint64][][]Field
+}
+
+// PushFields attaches fields to every record logged from the current goroutine, by any logger,
+// until a matching call to PopFields. It is meant for instrumenting code paths that can't be given
+// a logger or fields explicitly:
+//
+//	log.PushFields(log.F("request", id))
+//	defer log.PopFields()
+//
+// The fields aren't inherited by goroutines started by the current one.
+func PushFields(fields ...Field) {
+	id := goroutineID()
+	ambient.mu.Lock()
+	defer ambient.mu.Unlock()
+
+	if ambient.fields == nil {
+		ambient.fields = map[uint64][][]Field{}
+	}
+	ambient.fields[id] = append(ambient.fields[id], append([]Field(nil), fields...))
+}
+
+// PopFields removes the fields most recently pushed by the current goroutine with PushFields. It
+// does nothing if there are none.
+func PopFields() {
+	id := goroutineID()
+	ambient.mu.Lock()
+	defer ambient.mu.Unlock()
+
+	stack := ambient.fields[id]
+	switch len(stack) {
+	case 0:
+	case 1:
+		delete(ambient.fields, id)
+	default:
+		ambient.fields[id] = stack[:len(stack)-1]
+	}
+}
+
+// ambientFields returns the fields pushed by the current goroutine, oldest first.
+func ambientFields() []Field {
+	ambient.mu.Lock()
+	empty := len(ambient.fields) == 0
+	ambient.mu.Unlock()
+	// Looking up the goroutine ID is comparatively expensive, so don't unless someone pushed.
+	if empty {
+		return nil
+	}
+
+	id := goroutineID()
+	ambient.mu.Lock()
+	defer ambient.mu.Unlock()
+
+	var fields []Field
+	for _, pushed := range ambient.fields[id] {
+		fields = append(fields, pushed...)
+	}
+	return fields
+}
+
+// goroutineID returns the ID of the current goroutine, parsed from the header of its stack trace,
+// or 0 if it can't be determined.
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
@@ -0,0 +1,144 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrWriterClosed is returned by writes to an AsyncWriter after it has been closed.
+var ErrWriterClosed = errors.New("writer is closed")
+
+// AsyncWriter writes to another writer from a goroutine of its own, through a bounded queue, so
+// that a slow destination such as a network connection doesn't hold up the logger or its other
+// destinations. Each AsyncWriter has its own queue, so several of them can be used to fan records
+// out to several destinations in parallel. Writes of records keep their order, and records queued
+// while the destination is busy are coalesced into a single write.
+type AsyncWriter struct {
+	w            io.Writer
+	queue        chan asyncWrite
+	dropWhenFull bool
+	dropped      atomic.Int64
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+	err    atomic.Value
+}
+
+// asyncWrite is a queued write, or a flush request if flushed is not nil.
+type asyncWrite struct {
+	p       []byte
+	flushed chan struct{}
+}
+
+// NewAsyncWriter returns an AsyncWriter writing to w through a queue of queueSize records. When the
+// queue is full, records are dropped if dropWhenFull is set, and writes block until there is room
+// otherwise.
+func NewAsyncWriter(w io.Writer, queueSize int, dropWhenFull bool) *AsyncWriter {
+	a := &AsyncWriter{
+		w:            w,
+		queue:        make(chan asyncWrite, queueSize),
+		dropWhenFull: dropWhenFull,
+		done:         make(chan struct{}),
+	}
+	queues.Store(a, func() int { return len(a.queue) })
+	go a.run()
+	return a
+}
+
+// maxAsyncBatch is the size in bytes above which an AsyncWriter stops coalescing queued records.
+const maxAsyncBatch = 64 << 10
+
+// run writes queued records until the queue is closed. Records that are already queued when the
+// destination is ready are coalesced into a single write.
+func (a *AsyncWriter) run() {
+	defer close(a.done)
+	var batch []byte
+	for req := range a.queue {
+		batch = append(batch[:0], req.p...)
+		for len(batch) < maxAsyncBatch && req.flushed == nil {
+			var ok bool
+			select {
+			case req, ok = <-a.queue:
+			default:
+			}
+			if !ok {
+				break
+			}
+			batch = append(batch, req.p...)
+		}
+		if len(batch) > 0 {
+			if _, err := a.w.Write(batch); err != nil {
+				a.err.Store(err)
+				reportWriteError(err)
+			}
+		}
+		if req.flushed != nil {
+			close(req.flushed)
+		}
+	}
+}
+
+// Write queues a copy of p to be written. It never reports errors of the destination; see Err.
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return 0, ErrWriterClosed
+	}
+
+	req := asyncWrite{p: append([]byte(nil), p...)}
+	if !a.dropWhenFull {
+		a.queue <- req
+		return len(p), nil
+	}
+	select {
+	case a.queue <- req:
+	default:
+		a.dropped.Add(1)
+		reportDropped("queue", 1)
+	}
+	return len(p), nil
+}
+
+// Flush waits until all records queued before the call have been written.
+func (a *AsyncWriter) Flush() error {
+	a.mu.RLock()
+	if a.closed {
+		a.mu.RUnlock()
+		return nil
+	}
+	flushed := make(chan struct{})
+	a.queue <- asyncWrite{flushed: flushed}
+	a.mu.RUnlock()
+
+	<-flushed
+	return a.Err()
+}
+
+// Close writes all queued records and stops the writer. It doesn't close the destination.
+func (a *AsyncWriter) Close() error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+
+	<-a.done
+	queues.Delete(a)
+	return a.Err()
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (a *AsyncWriter) Dropped() int64 {
+	return a.dropped.Load()
+}
+
+// Err returns the last error returned by the destination, if any.
+func (a *AsyncWriter) Err() error {
+	err, _ := a.err.Load().(error)
+	return err
+}
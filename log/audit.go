@@ -0,0 +1,85 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"math"
+)
+
+// AuditEvent describes a security-relevant event. Every field is required.
+type AuditEvent struct {
+	// Actor identifies who performed the action, such as a user or service account.
+	Actor string
+	// Action is what the actor did, such as "delete" or "grant-role".
+	Action string
+	// Target identifies what the action was performed on.
+	Target string
+	// Outcome is the result of the action, such as "success" or "denied".
+	Outcome string
+}
+
+// auditKeys are the keys of the fields of audit records reserved for the AuditEvent.
+var auditKeys = map[string]bool{"actor": true, "action": true, "target": true, "outcome": true}
+
+// fields returns the event as the leading fields of an audit record.
+func (e AuditEvent) fields() []Field {
+	return []Field{
+		{Key: "actor", Value: e.Actor},
+		{Key: "action", Value: e.Action},
+		{Key: "target", Value: e.Target},
+		{Key: "outcome", Value: e.Outcome},
+	}
+}
+
+// validate returns an error if any of the event's fields are missing.
+func (e AuditEvent) validate() error {
+	for _, f := range e.fields() {
+		if f.Value == "" {
+			return errors.New("audit event is missing " + f.Key)
+		}
+	}
+	return nil
+}
+
+// Audit implements the Logger interface.
+func (l *logger) Audit(event AuditEvent, fields ...Field) error {
+	if err := event.validate(); err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if auditKeys[f.Key] {
+			return errors.New("audit field " + f.Key + " is reserved for the audit event")
+		}
+	}
+
+	// Audit records are never suppressed, whatever the logger's verbosity. Skip logwDepth and
+	// Audit, plus the package-level function for the default logger.
+	l.logwDepth(l.callerSkip-1, math.MinInt, AuditLevel, "audit", append(event.fields(), fields...))
+	return nil
+}
+
+// auditRecordFields is like recordFields for audit records, whose fields start with those of the
+// AuditEvent. Fields of the logger with keys reserved for the event are dropped, so that nothing but
+// the event decides who did what. It must be called with l.mu held.
+func (l *logger) auditRecordFields(fields []Field) []Field {
+	var all []Field
+	for _, f := range l.recordFields(nil) {
+		if !auditKeys[f.Key] {
+			all = append(all, f)
+		}
+	}
+	return dedupeFields(append(all, fields...), l.duplicateKeys)
+}
+
+// SetAuditOutput implements the Logger interface.
+func (l *logger) SetAuditOutput(writers ...io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.auditWriters = append([]io.Writer(nil), writers...)
+}
+
+// Audit is a convenience method that calls defaultLogger.Audit(event, fields...)
+func Audit(event AuditEvent, fields ...Field) error {
+	return defaultLogger.Audit(event, fields...)
+}
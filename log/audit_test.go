@@ -0,0 +1,37 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAuditReservedKeys(t *testing.T) {
+	event := AuditEvent{Actor: "alice", Action: "delete", Target: "report", Outcome: "success"}
+	for _, policy := range []DuplicateKeyPolicy{DuplicateKeysLastWins, DuplicateKeysFirstWins, DuplicateKeysSuffix} {
+		t.Run(policy.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			l := NewLogger(false, false, false, &buf)
+			l.SetDuplicateKeyPolicy(policy)
+			l = l.With(F("actor", "mallory"), F("request", 7))
+
+			if err := l.Audit(event, F("actor", "mallory")); err == nil {
+				t.Error("Audit accepted a field with a reserved key")
+			}
+			if buf.Len() != 0 {
+				t.Errorf("Audit wrote a rejected record: %q", buf.String())
+			}
+
+			if err := l.Audit(event, F("reason", "expired")); err != nil {
+				t.Fatalf("Audit: %v", err)
+			}
+			got := buf.String()
+			if !strings.Contains(got, "actor=alice") || strings.Contains(got, "mallory") {
+				t.Errorf("audit record doesn't attribute the action to the event's actor: %q", got)
+			}
+			if !strings.Contains(got, "request=7") || !strings.Contains(got, "reason=expired") {
+				t.Errorf("audit record is missing other fields: %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+)
+
+// modulePath is the path of the module containing this package.
+const modulePath = "github.com/crunchyroll/multilog"
+
+// configFields describes the effective configuration of the default logger set up by Init, which
+// opened logFile, or nothing if logFile is empty.
+func configFields(opts *LogOptions, logFile string) []Field {
+	if logFile == "" {
+		logFile = "none"
+	}
+	defaultLogger.mu.Lock()
+	stderr, format := defaultLogger.logToStderr, defaultLogger.format
+	writers := writerTypes(defaultLogger.writers)
+	auditWriters := writerTypes(defaultLogger.auditWriters)
+	defaultLogger.mu.Unlock()
+
+	return []Field{
+		{Key: "version", Value: moduleVersion()},
+		{Key: "go", Value: runtime.Version()},
+		{Key: "verbosity", Value: opts.Verbosity},
+		{Key: "stderr", Value: stderr},
+		{Key: "writers", Value: writers},
+		{Key: "colorful", Value: opts.Colorful},
+		{Key: "timestamp", Value: opts.Timestamp},
+		{Key: "log_dir", Value: logBase},
+		{Key: "log_file", Value: logFile},
+		{Key: "stderr_only", Value: opts.StderrOnly},
+		{Key: "container", Value: opts.Container.enabled()},
+		{Key: "split_console", Value: opts.SplitConsole},
+		{Key: "hijack_stderr", Value: opts.HijackStderr},
+		{Key: "fatal", Value: opts.FatalBehavior},
+		{Key: "fatal_stacks", Value: opts.FatalStacks},
+		{Key: "crash_report_dir", Value: opts.CrashReportDir},
+		{Key: "production", Value: opts.Production},
+		{Key: "development", Value: opts.Development},
+		{Key: "redact_keys", Value: len(opts.RedactKeys)},
+		{Key: "scrub_rules", Value: len(opts.ScrubRules)},
+		{Key: "scrubbers", Value: len(opts.Scrubbers)},
+		{Key: "field_providers", Value: len(opts.FieldProviders)},
+		{Key: "audit_writers", Value: auditWriters},
+		{Key: "max_message_size", Value: opts.MaxMessageSize},
+		{Key: "sanitize", Value: opts.Sanitize},
+		{Key: "multiline_markers", Value: opts.MultilineMarkers},
+		{Key: "duplicate_keys", Value: opts.DuplicateKeys},
+		{Key: "fingerprints", Value: opts.Fingerprints},
+		{Key: "pprof_labels", Value: len(opts.PprofLabels)},
+		{Key: "uptime", Value: opts.Uptime},
+		{Key: "delta", Value: opts.DeltaMode},
+		{Key: "quotas", Value: len(opts.Quotas)},
+		{Key: "format", Value: format},
+		{Key: "fields", Value: len(opts.Fields)},
+	}
+}
+
+// writerTypes describes the destinations writers by their types, such as "*os.File" or
+// "*log.NewRelicWriter".
+func writerTypes(writers []io.Writer) []string {
+	types := make([]string, len(writers))
+	for i, w := range writers {
+		types[i] = fmt.Sprintf("%T", w)
+	}
+	return types
+}
+
+// moduleVersion returns the version of this module built into the running binary, if known.
+func moduleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if info.Main.Path == modulePath {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,84 @@
+package log
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// BatchWriter coalesces records into larger writes to another writer, cutting the number of write
+// system calls made by programs that log many records per second. Buffered records are written
+// once they exceed a size, or at the latest after a maximum latency. Records are never split
+// across writes, so a BatchWriter can be used with files in shared mode.
+type BatchWriter struct {
+	w          io.Writer
+	maxBytes   int
+	maxLatency time.Duration
+
+	mu    sync.Mutex
+	buf   []byte
+	timer *time.Timer
+	err   error
+}
+
+// NewBatchWriter returns a BatchWriter writing to w whenever more than maxBytes are buffered, or
+// maxLatency after the first record of a batch was buffered.
+func NewBatchWriter(w io.Writer, maxBytes int, maxLatency time.Duration) *BatchWriter {
+	return &BatchWriter{w: w, maxBytes: maxBytes, maxLatency: maxLatency}
+}
+
+// Write buffers p. It returns the error of the last failed write to the destination, if it hasn't
+// been returned yet.
+func (b *BatchWriter) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.buf) > 0 && len(b.buf)+len(p) > b.maxBytes {
+		b.flushLocked()
+	}
+	b.buf = append(b.buf, p...)
+	if len(b.buf) >= b.maxBytes {
+		b.flushLocked()
+	} else if b.timer == nil {
+		b.timer = time.AfterFunc(b.maxLatency, b.flushOnTimer)
+	}
+
+	err := b.err
+	b.err = nil
+	return len(p), err
+}
+
+// Flush writes the buffered records.
+func (b *BatchWriter) Flush() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.flushLocked()
+	err := b.err
+	b.err = nil
+	return err
+}
+
+// flushOnTimer writes the buffered records once the maximum latency has passed, leaving any error
+// to be returned by the next call to Write or Flush.
+func (b *BatchWriter) flushOnTimer() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.flushLocked()
+}
+
+// flushLocked writes the buffered records. It must be called with b.mu held.
+func (b *BatchWriter) flushLocked() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if len(b.buf) == 0 {
+		return
+	}
+	if _, err := b.w.Write(b.buf); err != nil {
+		b.err = err
+	}
+	b.buf = b.buf[:0]
+}
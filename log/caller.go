@@ -0,0 +1,48 @@
+package log
+
+import (
+	"runtime"
+	"sync"
+)
+
+// callerSite is the resolved location of a program counter.
+type callerSite struct {
+	function string
+	file     string
+	line     int
+}
+
+// callerSites caches the locations of the program counters of logging calls, keyed by program
+// counter. Logging calls are made from a fixed set of call sites, so it stays small, and resolving
+// a program counter is much more expensive than finding it.
+var callerSites sync.Map
+
+// callerFrame returns the location of a function on the calling goroutine's stack, with the same
+// skip as runtime.Caller: a skip of 0 identifies the caller of callerFrame. Frames are resolved with
+// runtime.CallersFrames, so functions inlined into their callers are attributed correctly.
+func callerFrame(skip int) (callerSite, bool) {
+	var pcs [1]uintptr
+	// Skip runtime.Callers and callerFrame itself.
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return callerSite{}, false
+	}
+	pc := pcs[0]
+	if site, ok := callerSites.Load(pc); ok {
+		return site.(callerSite), true
+	}
+
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	if frame.PC == 0 {
+		return callerSite{}, false
+	}
+	site := callerSite{function: frame.Function, file: frame.File, line: frame.Line}
+	callerSites.Store(pc, site)
+	return site, true
+}
+
+// caller is like runtime.Caller, but caches the location of each call site. A skip of 0
+// identifies the caller of caller.
+func caller(skip int) (file string, line int, ok bool) {
+	site, ok := callerFrame(skip + 1)
+	return site.file, site.line, ok
+}
@@ -0,0 +1,101 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// CEFOptions configures records written in FormatCEF.
+type CEFOptions struct {
+	// Vendor, Product and Version identify the device in the CEF header. Product defaults to the
+	// service name.
+	Vendor  string
+	Product string
+	Version string
+
+	// Severity maps levels to CEF severities from 0 to 10. Levels that aren't mapped use the
+	// logger's severity mapping.
+	Severity map[Level]int
+
+	// Extensions maps field keys to the CEF extension keys they are written as, such as "suser" for
+	// "actor". Fields that aren't mapped use DefaultCEFExtensions, and failing that are written
+	// under their own key, with characters CEF doesn't allow in keys replaced by underscores.
+	Extensions map[string]string
+
+	// SignatureKey is the key of the field whose value is used as the signature ID of a record,
+	// such as "action" for audit records. Records without the field use the name of their level.
+	SignatureKey string
+}
+
+// DefaultCEFExtensions maps the fields of audit records to CEF extension keys for fields not
+// mapped by CEFOptions.Extensions.
+var DefaultCEFExtensions = map[string]string{
+	"actor":   "suser",
+	"action":  "act",
+	"outcome": "outcome",
+	"target":  "duid",
+}
+
+// cefHeaderEscaper escapes the characters with special meaning in CEF header fields.
+var cefHeaderEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+
+// cefValueEscaper escapes the characters with special meaning in CEF extension values.
+var cefValueEscaper = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+
+// encodeCEF returns r as a CEF record on its own line, with the cleaned msg and fields.
+func (l *logger) encodeCEF(r *Record, msg string, fields []Field) []byte {
+	opts := l.cefOptions
+	product := opts.Product
+	if product == "" {
+		product = l.serviceName
+	}
+	signature := r.Level.String()
+	for _, f := range fields {
+		if opts.SignatureKey != "" && f.Key == opts.SignatureKey {
+			signature = fmt.Sprint(f.Value)
+			break
+		}
+	}
+	severity, ok := opts.Severity[r.Level]
+	if !ok {
+		severity = l.severityOf(r).CEF
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "CEF:0|%s|%s|%s|%s|%s|%d|", cefHeaderEscaper.Replace(opts.Vendor),
+		cefHeaderEscaper.Replace(product), cefHeaderEscaper.Replace(opts.Version),
+		cefHeaderEscaper.Replace(signature), cefHeaderEscaper.Replace(msg), severity)
+
+	fmt.Fprintf(&b, "rt=%d dproc=%s cs1Label=caller cs1=%s:%d", r.Time.UnixMilli(),
+		cefValueEscaper.Replace(l.serviceName), cefValueEscaper.Replace(r.File), r.Line)
+	for _, f := range fields {
+		b.WriteByte(' ')
+		b.WriteString(cefKey(f.Key, opts.Extensions))
+		b.WriteByte('=')
+		b.WriteString(cefValueEscaper.Replace(fmt.Sprint(f.Value)))
+	}
+	if r.Stacks != nil {
+		b.WriteString(" cs2Label=stacks cs2=")
+		b.WriteString(cefValueEscaper.Replace(string(r.Stacks)))
+	}
+	b.WriteByte('\n')
+	return b.Bytes()
+}
+
+// cefKey returns the CEF extension key for the field key.
+func cefKey(key string, extensions map[string]string) string {
+	if ext, ok := extensions[key]; ok {
+		return ext
+	}
+	if ext, ok := DefaultCEFExtensions[key]; ok {
+		return ext
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, key)
+}
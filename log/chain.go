@@ -0,0 +1,220 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// chainMarker separates a record from the hash that chains it to the previous record.
+	chainMarker = " chain="
+	// checkpointPrefix starts checkpoint lines written by chain writers.
+	checkpointPrefix = "#checkpoint "
+	// maxChainLine bounds the length of a single line read by VerifyChain.
+	maxChainLine = 64 << 20
+)
+
+// ChainWriter makes a log file tamper-evident. It appends a hash to every record that covers the
+// record and the hash of the previous record, so modifying or removing any record breaks the chain
+// from that point on. Every so often it also writes a checkpoint line holding the current hash,
+// signed with an ed25519 key when one is provided, so that the chain can't simply be recomputed
+// after tampering. Use VerifyChain to check a file.
+type ChainWriter struct {
+	mu       sync.Mutex
+	w        io.Writer
+	key      ed25519.PrivateKey
+	interval int
+	seq      int
+	hash     [sha256.Size]byte
+}
+
+// NewChainWriter returns a ChainWriter writing to w that writes a checkpoint after every interval
+// records, or never if interval <= 0. Checkpoints are signed with key unless it is nil.
+func NewChainWriter(w io.Writer, key ed25519.PrivateKey, interval int) *ChainWriter {
+	return &ChainWriter{w: w, key: key, interval: interval}
+}
+
+// Write implements io.Writer. Each call is treated as a single record.
+func (c *ChainWriter) Write(p []byte) (int, error) {
+	record := bytes.TrimSuffix(p, []byte("\n"))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.hash = chainHash(c.hash, record)
+	c.seq++
+
+	buf := make([]byte, 0, len(record)+len(chainMarker)+2*sha256.Size+1)
+	buf = append(buf, record...)
+	buf = append(buf, chainMarker...)
+	buf = hex.AppendEncode(buf, c.hash[:])
+	buf = append(buf, '\n')
+	if c.interval > 0 && c.seq%c.interval == 0 {
+		buf = append(buf, c.checkpoint(false)...)
+	}
+
+	if _, err := c.w.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close writes a final checkpoint, marking the end of the chain. Files whose chain doesn't end in
+// a final checkpoint were either still being written or have been truncated. Close doesn't close
+// the underlying writer.
+func (c *ChainWriter) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err := io.WriteString(c.w, c.checkpoint(true))
+	return err
+}
+
+// checkpoint returns a checkpoint line for the current state of the chain.
+func (c *ChainWriter) checkpoint(final bool) string {
+	body := checkpointBody(c.seq, c.hash[:], final)
+	if c.key == nil {
+		return checkpointPrefix + body + "\n"
+	}
+	return checkpointPrefix + body + " sig=" + hex.EncodeToString(ed25519.Sign(c.key, []byte(body))) + "\n"
+}
+
+// checkpointBody returns the signed portion of a checkpoint line.
+func checkpointBody(seq int, hash []byte, final bool) string {
+	body := fmt.Sprintf("seq=%d hash=%x", seq, hash)
+	if final {
+		body += " final"
+	}
+	return body
+}
+
+// chainHash returns the hash chaining record to the record with hash prev.
+func chainHash(prev [sha256.Size]byte, record []byte) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write(prev[:])
+	h.Write(record)
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// ChainStatus describes a log file verified by VerifyChain.
+type ChainStatus struct {
+	// Records is the number of records in the chain.
+	Records int
+	// Checkpoints is the number of checkpoints found.
+	Checkpoints int
+	// Final reports whether the chain ends with the final checkpoint written by ChainWriter.Close.
+	Final bool
+}
+
+// VerifyChain reads a log file written through a ChainWriter from r and checks that every record
+// and checkpoint is consistent with the records before it. A file that verifies but doesn't end
+// with a final checkpoint may have been truncated since its last checkpoint.
+//
+// If pub is not nil, checkpoints must also carry a valid signature by the corresponding private
+// key, and since an unsigned chain can be recomputed by anyone, the file must have at least one
+// checkpoint, with no more records after the last one than the checkpoint interval, taken from the
+// first checkpoint. Without pub, callers must check Checkpoints themselves.
+func VerifyChain(r io.Reader, pub ed25519.PublicKey) (ChainStatus, error) {
+	var (
+		status ChainStatus
+		hash   [sha256.Size]byte
+		record []string
+		lineNo int
+		// records at the first and last checkpoints.
+		interval, checkpointed int
+	)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxChainLine)
+	for scanner.Scan() {
+		line := scanner.Text()
+		lineNo++
+		if status.Final {
+			return status, fmt.Errorf("line %d: data after final checkpoint", lineNo)
+		}
+
+		if len(record) == 0 && strings.HasPrefix(line, checkpointPrefix) {
+			if err := verifyCheckpoint(line, status.Records, hash[:], pub, &status); err != nil {
+				return status, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			if status.Checkpoints == 0 {
+				interval = status.Records
+			}
+			status.Checkpoints++
+			checkpointed = status.Records
+			continue
+		}
+
+		// Records may span several lines. Only the last one carries the chain hash.
+		i := strings.LastIndex(line, chainMarker)
+		if i < 0 || len(line)-i-len(chainMarker) != 2*sha256.Size {
+			record = append(record, line)
+			continue
+		}
+		record = append(record, line[:i])
+		hash = chainHash(hash, []byte(strings.Join(record, "\n")))
+		record = record[:0]
+		status.Records++
+		if line[i+len(chainMarker):] != hex.EncodeToString(hash[:]) {
+			return status, fmt.Errorf("line %d: chain hash mismatch", lineNo)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return status, err
+	}
+	if len(record) > 0 {
+		return status, errors.New("file ends with an incomplete record")
+	}
+	if pub != nil {
+		switch {
+		case status.Checkpoints == 0:
+			return status, errors.New("no signed checkpoint")
+		case status.Records-checkpointed > interval:
+			return status, fmt.Errorf("%d records after the last signed checkpoint, expected one every %d",
+				status.Records-checkpointed, interval)
+		}
+	}
+	return status, nil
+}
+
+// verifyCheckpoint checks a checkpoint line against the chain state after records records.
+func verifyCheckpoint(line string, records int, hash []byte, pub ed25519.PublicKey, status *ChainStatus) error {
+	body := strings.TrimPrefix(line, checkpointPrefix)
+	var sig string
+	if i := strings.Index(body, " sig="); i >= 0 {
+		body, sig = body[:i], body[i+len(" sig="):]
+	}
+
+	fields := strings.Fields(body)
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "seq=") {
+		return errors.New("malformed checkpoint")
+	}
+	seq, err := strconv.Atoi(strings.TrimPrefix(fields[0], "seq="))
+	if err != nil {
+		return errors.New("malformed checkpoint")
+	}
+	final := len(fields) == 3 && fields[2] == "final"
+	if seq != records || body != checkpointBody(records, hash, final) {
+		return errors.New("checkpoint doesn't match the chain")
+	}
+
+	if pub != nil {
+		signature, err := hex.DecodeString(sig)
+		if err != nil || !ed25519.Verify(pub, []byte(body), signature) {
+			return errors.New("invalid checkpoint signature")
+		}
+	}
+	status.Final = final
+	return nil
+}
@@ -0,0 +1,179 @@
+package log
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"strings"
+	"testing"
+)
+
+// writeChain returns the file written by a ChainWriter given records, checkpointing every interval
+// records and signing checkpoints with key, closing the chain if close is set.
+func writeChain(t *testing.T, records []string, key ed25519.PrivateKey, interval int, close bool) string {
+	t.Helper()
+	var buf bytes.Buffer
+	c := NewChainWriter(&buf, key, interval)
+	for _, r := range records {
+		if _, err := c.Write([]byte(r + "\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if close {
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.String()
+}
+
+// chainLines returns the lines of a chained file, without their newlines.
+func chainLines(file string) []string {
+	return strings.Split(strings.TrimSuffix(file, "\n"), "\n")
+}
+
+// joinLines is the inverse of chainLines.
+func joinLines(lines []string) string {
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func TestVerifyChain(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := []string{"record 1", "record 2", "record 3", "record 4", "record 5"}
+	// With a checkpoint every 2 records, the lines are: record 1, record 2, checkpoint, record 3,
+	// record 4, checkpoint, record 5, final checkpoint.
+	signed := writeChain(t, records, key, 2, true)
+	lines := chainLines(signed)
+
+	tests := []struct {
+		name    string
+		file    string
+		pub     ed25519.PublicKey
+		want    ChainStatus
+		wantErr string
+	}{
+		{
+			name: "intact",
+			file: writeChain(t, records, nil, 2, true),
+			want: ChainStatus{Records: 5, Checkpoints: 3, Final: true},
+		},
+		{
+			name: "intact signed",
+			file: signed,
+			pub:  pub,
+			want: ChainStatus{Records: 5, Checkpoints: 3, Final: true},
+		},
+		{
+			name: "multi-line record",
+			file: writeChain(t, []string{"first", "second\ncontinued", "third"}, nil, 0, true),
+			want: ChainStatus{Records: 3, Checkpoints: 1, Final: true},
+		},
+		{
+			name: "empty",
+			file: "",
+		},
+		{
+			name:    "tampered record",
+			file:    strings.Replace(signed, "record 3", "record X", 1),
+			pub:     pub,
+			wantErr: "line 4: chain hash mismatch",
+		},
+		{
+			name:    "reordered records",
+			file:    joinLines(append([]string{lines[1], lines[0]}, lines[2:]...)),
+			pub:     pub,
+			wantErr: "line 1: chain hash mismatch",
+		},
+		{
+			name:    "removed record",
+			file:    joinLines(append(append([]string(nil), lines[:3]...), lines[4:]...)),
+			pub:     pub,
+			wantErr: "line 4: chain hash mismatch",
+		},
+		{
+			name:    "recomputed chain without the key",
+			file:    writeChain(t, []string{"record 1", "forged"}, nil, 2, true),
+			pub:     pub,
+			wantErr: "line 3: invalid checkpoint signature",
+		},
+		{
+			name:    "checkpoints removed and chain recomputed",
+			file:    writeChain(t, records, nil, 0, false),
+			pub:     pub,
+			want:    ChainStatus{Records: 5},
+			wantErr: "no signed checkpoint",
+		},
+		{
+			name:    "checkpoints removed after the first",
+			file:    joinLines(append(append([]string(nil), lines[:5]...), lines[6])),
+			pub:     pub,
+			want:    ChainStatus{Records: 5, Checkpoints: 1},
+			wantErr: "3 records after the last signed checkpoint, expected one every 2",
+		},
+		{
+			name: "unsigned chain without a key",
+			file: writeChain(t, records, nil, 0, false),
+			want: ChainStatus{Records: 5},
+		},
+		{
+			name:    "signed with another key",
+			file:    signed,
+			pub:     otherPub,
+			wantErr: "line 3: invalid checkpoint signature",
+		},
+		{
+			name:    "tampered checkpoint",
+			file:    strings.Replace(signed, "#checkpoint seq=2", "#checkpoint seq=1", 1),
+			pub:     pub,
+			wantErr: "line 3: checkpoint doesn't match the chain",
+		},
+		{
+			name: "truncated after a checkpoint",
+			file: joinLines(lines[:6]),
+			pub:  pub,
+			want: ChainStatus{Records: 4, Checkpoints: 2},
+		},
+		{
+			name: "truncated between checkpoints",
+			file: joinLines(lines[:7]),
+			pub:  pub,
+			want: ChainStatus{Records: 5, Checkpoints: 2},
+		},
+		{
+			name:    "truncated within a record",
+			file:    joinLines(lines[:6]) + "record 5 cha",
+			pub:     pub,
+			want:    ChainStatus{Records: 4, Checkpoints: 2},
+			wantErr: "file ends with an incomplete record",
+		},
+		{
+			name:    "data after final checkpoint",
+			file:    signed + "record 6\n",
+			pub:     pub,
+			want:    ChainStatus{Records: 5, Checkpoints: 3, Final: true},
+			wantErr: "line 9: data after final checkpoint",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := VerifyChain(strings.NewReader(tt.file), tt.pub)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("VerifyChain: %v", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Fatalf("VerifyChain returned error %v, want %q", err, tt.wantErr)
+			}
+			if tt.wantErr == "" || tt.want != (ChainStatus{}) {
+				if status != tt.want {
+					t.Errorf("VerifyChain returned %+v, want %+v", status, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package log
+
+import "io"
+
+// Option overrides a setting of a logger created by Logger.Clone.
+type Option func(*logger)
+
+// WithVerbosity sets the verbosity required to output logging messages.
+func WithVerbosity(verbosity int) Option {
+	return func(l *logger) { l.verbosity, l.ownVerbosity = verbosity, true }
+}
+
+// WithDefaultVerbosity sets the verbosity of logging calls that don't specify one.
+func WithDefaultVerbosity(verbosity int) Option {
+	return func(l *logger) { l.defaultVerbosity = verbosity }
+}
+
+// WithStderr sets whether records are written to stderr.
+func WithStderr(logToStderr bool) Option {
+	return func(l *logger) { l.logToStderr = logToStderr }
+}
+
+// WithColorful sets whether records written to stderr are colorful.
+func WithColorful(colorful bool) Option {
+	return func(l *logger) { l.colorful = colorful }
+}
+
+// WithTimestamp sets whether records include a timestamp.
+func WithTimestamp(timestamp bool) Option {
+	return func(l *logger) { l.timestamp = timestamp }
+}
+
+// WithCallerSkip skips n more stack frames when reporting the file and line of logging calls, for
+// wrappers and adapters that log on behalf of their own callers.
+func WithCallerSkip(n int) Option {
+	return func(l *logger) { l.callerSkip += n }
+}
+
+// WithWriters replaces the destinations of the logger, including any inherited from a parent, with
+// writers. Without any writers, the logger only writes to stderr.
+func WithWriters(writers ...io.Writer) Option {
+	return func(l *logger) { l.writers, l.ownWriters = append([]io.Writer(nil), writers...), true }
+}
+
+// WithExtraWriters adds writers to the destinations of the logger.
+func WithExtraWriters(writers ...io.Writer) Option {
+	return func(l *logger) { l.writers = append(l.writers, writers...) }
+}
+
+// clone returns a copy of c that doesn't share any slices with it, so that changing the
+// destinations or hooks of one logger doesn't affect the other.
+func (c loggerConfig) clone() loggerConfig {
+	c.writers = append([]io.Writer(nil), c.writers...)
+	c.auditWriters = append([]io.Writer(nil), c.auditWriters...)
+	c.fatalHooks = append([]func(){}, c.fatalHooks...)
+	c.scrubRules = append([]ScrubRule(nil), c.scrubRules...)
+	c.scrubbers = append([]Scrubber(nil), c.scrubbers...)
+	c.fields = append([]Field(nil), c.fields...)
+	c.highlightRules = append([]HighlightRule(nil), c.highlightRules...)
+	c.quotas = append([]Quota(nil), c.quotas...)
+	c.pprofLabels = append([]string(nil), c.pprofLabels...)
+	c.fieldProviders = append([]FieldProvider(nil), c.fieldProviders...)
+	c.ownMetrics = false
+	return c
+}
+
+// Clone implements the Logger interface.
+func (l *logger) Clone(opts ...Option) Logger {
+	l.mu.Lock()
+	c := &logger{
+		count:        map[Level]int64{},
+		loggerConfig: l.loggerConfig.clone(),
+	}
+	l.mu.Unlock()
+
+	// A clone is used directly, never through the package-level convenience functions, so it
+	// doesn't skip their frame. Frames skipped with WithCallerSkip are inherited, so that loggers
+	// derived from the logger of an adapter keep reporting the adapter's caller.
+	if l == defaultLogger {
+		c.callerSkip--
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Clone is a convenience method that calls defaultLogger.Clone(opts...)
+func Clone(opts ...Option) Logger {
+	return defaultLogger.Clone(opts...)
+}
+
+// Named implements the Logger interface.
+func (l *logger) Named(name string) Logger {
+	c := l.Clone().(*logger)
+	if c.name != "" {
+		name = c.name + "." + name
+	}
+	c.name, c.parent = name, l
+	c.writers, c.ownWriters, c.ownVerbosity = nil, false, false
+	return c
+}
+
+// inheritedVerbosity returns the verbosity in effect for l, walking up its parents until one has
+// its own verbosity. It must be called with l.mu held, and locks each parent in turn; parents
+// never lock their children, so this can't deadlock.
+func (l *logger) inheritedVerbosity() int {
+	if l.parent == nil || l.ownVerbosity {
+		return l.verbosity
+	}
+	l.parent.mu.Lock()
+	defer l.parent.mu.Unlock()
+	return l.parent.inheritedVerbosity()
+}
+
+// inheritedWriters returns the writers l writes to: its own, preceded by those of its parents
+// unless it has replaced them. It must be called with l.mu held. The returned slice must not be
+// modified.
+func (l *logger) inheritedWriters() []io.Writer {
+	if l.parent == nil || l.ownWriters {
+		return l.writers
+	}
+	l.parent.mu.Lock()
+	writers := l.parent.inheritedWriters()
+	l.parent.mu.Unlock()
+	if len(l.writers) == 0 {
+		return writers
+	}
+	return append(writers[:len(writers):len(writers)], l.writers...)
+}
+
+// Named is a convenience method that calls defaultLogger.Named(name)
+func Named(name string) Logger {
+	return defaultLogger.Named(name)
+}
@@ -0,0 +1,47 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wrapper logs on behalf of its callers, like the adapters do.
+type wrapper struct {
+	l Logger
+}
+
+func (w wrapper) info(msg string) {
+	w.l.Info(msg)
+}
+
+func TestCloneKeepsCallerSkip(t *testing.T) {
+	tests := []struct {
+		name   string
+		derive func(Logger) Logger
+	}{
+		{name: "Clone", derive: func(l Logger) Logger { return l.Clone() }},
+		{name: "With", derive: func(l Logger) Logger { return l.With(F("request", 1)) }},
+		{name: "Named", derive: func(l Logger) Logger { return l.Named("db") }},
+		{name: "WithGroup", derive: func(l Logger) Logger { return l.WithGroup("db") }},
+		{name: "WithTime", derive: func(l Logger) Logger { return l.WithTime(time.Now()) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			base := NewLogger(false, false, false, &buf)
+			w := wrapper{l: tt.derive(base.Clone(WithCallerSkip(1)))}
+
+			_, _, line, _ := runtime.Caller(0)
+			w.info("hello")
+
+			want := fmt.Sprintf("clone_test.go:%d: hello", line+1)
+			if got := buf.String(); !strings.Contains(got, want) {
+				t.Errorf("record %q doesn't report the wrapper's caller %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package log
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+)
+
+// Cmd is an external command whose output is logged line by line, as created by Logger.Command. Its
+// fields and methods are those of exec.Cmd, except that Run and Wait also log the last line of
+// output if it doesn't end with a newline.
+type Cmd struct {
+	*exec.Cmd
+	stdout, stderr *LineWriter
+}
+
+// Command implements the Logger interface.
+func (l *logger) Command(ctx context.Context, name string, arg ...string) *Cmd {
+	file, line, ok := caller(l.callerSkip - 2)
+	fields := []Field{{Key: "command", Value: filepath.Base(name)}}
+	c := &Cmd{
+		Cmd:    exec.CommandContext(ctx, name, arg...),
+		stdout: &LineWriter{logLine: l.siteLogger(InfoLevel, fields, file, line, ok)},
+		stderr: &LineWriter{logLine: l.siteLogger(WarningLevel, fields, file, line, ok)},
+	}
+	c.Stdout, c.Stderr = c.stdout, c.stderr
+	return c
+}
+
+// Command is a convenience method that calls defaultLogger.Command(ctx, name, arg...)
+func Command(ctx context.Context, name string, arg ...string) *Cmd {
+	return defaultLogger.Command(ctx, name, arg...)
+}
+
+// Run starts the command and waits for it to complete, like exec.Cmd.Run.
+func (c *Cmd) Run() error {
+	if err := c.Start(); err != nil {
+		return err
+	}
+	return c.Wait()
+}
+
+// Wait waits for the command to exit and its output to be logged, like exec.Cmd.Wait.
+func (c *Cmd) Wait() error {
+	err := c.Cmd.Wait()
+	c.stdout.Flush()
+	c.stderr.Flush()
+	return err
+}
+
+// siteLogger returns a function logging lines as records of level with fields, at the default
+// verbosity, attributed to a fixed location in the program. It is used for output that isn't
+// written by the program itself, where the location that matters is where the output was wired
+// to the logger.
+func (l *logger) siteLogger(level Level, fields []Field, file string, line int, callerOK bool) func(string) {
+	return func(s string) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if l.defaultVerbosity > l.inheritedVerbosity() {
+			return
+		}
+		l.write(level, l.defaultVerbosity, s, fields, file, line, callerOK)
+	}
+}
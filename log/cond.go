@@ -0,0 +1,78 @@
+package log
+
+// Conditional logs messages only when a condition holds. Its methods do nothing, and don't format
+// their operands, when the condition is false. Conditionals are obtained from Logger.If or If.
+type Conditional struct {
+	l    *logger
+	cond bool
+}
+
+// conditionalSkip is the number of stack frames between logDepth or logfDepth and the caller of a
+// Conditional method.
+const conditionalSkip = 2
+
+// If implements the Logger interface.
+func (l *logger) If(cond bool) Conditional {
+	return Conditional{l: l, cond: cond}
+}
+
+// If is a convenience method that calls defaultLogger.If(cond)
+func If(cond bool) Conditional {
+	return defaultLogger.If(cond)
+}
+
+// Info is like Logger.Info if the condition is true.
+func (c Conditional) Info(a ...interface{}) {
+	if c.cond {
+		c.l.logDepth(conditionalSkip, c.l.defaultVerbosity, InfoLevel, a...)
+	}
+}
+
+// Infof is like Logger.Infof if the condition is true.
+func (c Conditional) Infof(format string, a ...interface{}) {
+	if c.cond {
+		c.l.logfDepth(conditionalSkip, c.l.defaultVerbosity, InfoLevel, format, a...)
+	}
+}
+
+// Warning is like Logger.Warning if the condition is true.
+func (c Conditional) Warning(a ...interface{}) {
+	if c.cond {
+		c.l.logDepth(conditionalSkip, c.l.defaultVerbosity, WarningLevel, a...)
+	}
+}
+
+// Warningf is like Logger.Warningf if the condition is true.
+func (c Conditional) Warningf(format string, a ...interface{}) {
+	if c.cond {
+		c.l.logfDepth(conditionalSkip, c.l.defaultVerbosity, WarningLevel, format, a...)
+	}
+}
+
+// Error is like Logger.Error if the condition is true.
+func (c Conditional) Error(a ...interface{}) {
+	if c.cond {
+		c.l.logDepth(conditionalSkip, c.l.defaultVerbosity, ErrorLevel, a...)
+	}
+}
+
+// Errorf is like Logger.Errorf if the condition is true.
+func (c Conditional) Errorf(format string, a ...interface{}) {
+	if c.cond {
+		c.l.logfDepth(conditionalSkip, c.l.defaultVerbosity, ErrorLevel, format, a...)
+	}
+}
+
+// Fatal is like Logger.Fatal if the condition is true.
+func (c Conditional) Fatal(a ...interface{}) {
+	if c.cond {
+		c.l.fatal(c.l.logDepth(conditionalSkip, 0, FatalLevel, a...))
+	}
+}
+
+// Fatalf is like Logger.Fatalf if the condition is true.
+func (c Conditional) Fatalf(format string, a ...interface{}) {
+	if c.cond {
+		c.l.fatal(c.l.logfDepth(conditionalSkip, 0, FatalLevel, format, a...))
+	}
+}
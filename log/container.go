@@ -0,0 +1,57 @@
+package log
+
+import (
+	"os"
+	"strconv"
+)
+
+// ContainerMode determines whether Init configures the default logger for running in a container,
+// where logs are expected on stdout rather than in files: no log file is created, and records are
+// written to stdout only, in a structured format.
+type ContainerMode int
+
+const (
+	// ContainerAuto enables container mode when the environment looks like a container platform.
+	// The MULTILOG_CONTAINER environment variable, if set to a boolean, overrides the detection.
+	ContainerAuto ContainerMode = iota
+	// ContainerOn always enables container mode.
+	ContainerOn
+	// ContainerOff never enables container mode.
+	ContainerOff
+)
+
+// String returns the name of the mode.
+func (m ContainerMode) String() string {
+	switch m {
+	case ContainerAuto:
+		return "auto"
+	case ContainerOn:
+		return "on"
+	case ContainerOff:
+		return "off"
+	}
+	return "ContainerMode(" + strconv.Itoa(int(m)) + ")"
+}
+
+// containerEnv are environment variables set by container platforms: Kubernetes, Cloud Run and
+// ECS.
+var containerEnv = []string{"KUBERNETES_SERVICE_HOST", "K_SERVICE", "ECS_CONTAINER_METADATA_URI_V4"}
+
+// enabled reports whether container mode is enabled by m in the current environment.
+func (m ContainerMode) enabled() bool {
+	switch m {
+	case ContainerOn:
+		return true
+	case ContainerOff:
+		return false
+	}
+	if on, err := strconv.ParseBool(os.Getenv("MULTILOG_CONTAINER")); err == nil {
+		return on
+	}
+	for _, env := range containerEnv {
+		if os.Getenv(env) != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CrashReportEnv lists the environment variables included in crash reports. Variables that aren't
+// set are omitted. Secrets must not be added, since reports aren't redacted beyond their records.
+var CrashReportEnv = []string{
+	"GOMAXPROCS", "GOMEMLIMIT", "GOGC", "GODEBUG", "GOTRACEBACK",
+	"HOSTNAME", "USER", "PWD", "LANG", "TZ",
+	"KUBERNETES_SERVICE_HOST", "ECS_CONTAINER_METADATA_URI_V4",
+}
+
+// recordRing holds the text lines of the last records logged. It has its own lock since it is
+// shared by clones of a logger, which have locks of their own.
+type recordRing struct {
+	mu    sync.Mutex
+	lines []string
+	next  int
+	full  bool
+}
+
+// newRecordRing returns a recordRing holding n records, or nil if n <= 0.
+func newRecordRing(n int) *recordRing {
+	if n <= 0 {
+		return nil
+	}
+	return &recordRing{lines: make([]string, n)}
+}
+
+// add adds the record s, replacing the oldest record if the ring is full.
+func (r *recordRing) add(s string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lines[r.next] = s
+	r.next = (r.next + 1) % len(r.lines)
+	r.full = r.full || r.next == 0
+}
+
+// records returns the records in the ring, oldest first.
+func (r *recordRing) records() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]string(nil), r.lines[:r.next]...)
+	}
+	return append(append([]string(nil), r.lines[r.next:]...), r.lines[:r.next]...)
+}
+
+// SetCrashReports implements the Logger interface.
+func (l *logger) SetCrashReports(dir string, recent int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.crashReportDir = dir
+	l.recent = nil
+	if dir != "" {
+		l.recent = newRecordRing(recent)
+	}
+}
+
+// SetCrashReports is a convenience method that calls defaultLogger.SetCrashReports(dir, recent)
+func SetCrashReports(dir string, recent int) {
+	defaultLogger.SetCrashReports(dir, recent)
+}
+
+// writeCrashReport writes a crash report for the fatal record r, whose text line is s, to a new
+// file in the crash report directory. Failures are reported on stderr, since the logger can't log
+// while writing a record. It must be called with l.mu held.
+func (l *logger) writeCrashReport(r *Record, s string) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "crash report of %s (pid %d) at %s\n\n", l.serviceName, os.Getpid(),
+		r.Time.Format(time.RFC3339Nano))
+	fmt.Fprintf(&b, "record:\n%s\n\n", s)
+	fmt.Fprintf(&b, "command line:\n%q\n\n", os.Args)
+
+	b.WriteString("environment:\n")
+	for _, key := range CrashReportEnv {
+		if value, ok := os.LookupEnv(key); ok {
+			fmt.Fprintf(&b, "%s=%s\n", key, value)
+		}
+	}
+
+	if l.recent != nil {
+		b.WriteString("\nrecent records:\n")
+		for _, line := range l.recent.records() {
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+
+	b.WriteString("\ngoroutines:\n")
+	if r.Stacks != nil {
+		b.Write(r.Stacks)
+	} else {
+		b.Write(allStacks())
+	}
+
+	name := filepath.Join(l.crashReportDir, fmt.Sprintf("%s.crash.%s.%d.txt", fileNameSafe(l.serviceName),
+		r.Time.UTC().Format("20060102-150405.000000"), os.Getpid()))
+	if err := os.WriteFile(name, b.Bytes(), DefaultFileMode); err != nil {
+		fmt.Fprintf(consoleStderr.Load(), "unable to write crash report: %v\n", err)
+	}
+}
+
+// fileNameSafe returns s with every character other than letters, digits, dots, dashes and
+// underscores replaced by an underscore, so that it can't name a file outside a directory when
+// joined to it.
+func fileNameSafe(s string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		}
+		return '_'
+	}, s)
+	if strings.Trim(safe, ".") == "" {
+		return "_" + safe
+	}
+	return safe
+}
@@ -0,0 +1,278 @@
+package log
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDashboardRecords is the number of recent records a Dashboard keeps when none is given.
+const DefaultDashboardRecords = 1000
+
+// Dashboard is an http.Handler rendering the recent records of a logger as a web page, so that
+// operators can glance at a service's logs through its debug port:
+//
+//	dash := log.NewDashboard(nil, 0)
+//	defer dash.Close()
+//	http.Handle("/debug/logs", dash)
+//
+// The page is filtered by the query parameters "level", the minimum level of records to show, or
+// "audit" for audit records only, "component", a component path whose records and those of its
+// children are shown, and "q", text the message or fields of records must contain. Records are
+// shown as the logger's subscribers receive them, with sensitive data redacted and scrubbed.
+//
+// Requests with the query parameter "stream", or accepting only text/event-stream, get the records
+// passing the filter as they are written instead, as server-sent events holding their JSON form,
+// or their text form with "format=text". This makes it possible to tail a process's logs remotely:
+//
+//	curl -N 'http://localhost:6060/debug/logs?stream&level=warning&format=text'
+type Dashboard struct {
+	mu      sync.Mutex
+	records []Record
+	next    int
+	full    bool
+
+	l      Logger
+	cancel func()
+	done   chan struct{}
+}
+
+// DashboardKeepAlive is the interval at which a Dashboard sends comments on idle streams, so that
+// proxies don't close them.
+const DashboardKeepAlive = 15 * time.Second
+
+// NewDashboard returns a Dashboard keeping the last size records written by l, or by the default
+// logger if l is nil, from now on. A size <= 0 means DefaultDashboardRecords. The Dashboard must
+// be closed to end its subscription to the logger.
+func NewDashboard(l Logger, size int) *Dashboard {
+	if l == nil {
+		l = defaultLogger
+	}
+	if size <= 0 {
+		size = DefaultDashboardRecords
+	}
+	ch, cancel := l.Subscribe(nil)
+	d := &Dashboard{records: make([]Record, size), l: l, cancel: cancel, done: make(chan struct{})}
+	go d.run(ch)
+	return d
+}
+
+// run adds the records received from ch until it is closed.
+func (d *Dashboard) run(ch <-chan Record) {
+	defer close(d.done)
+	for r := range ch {
+		d.mu.Lock()
+		d.records[d.next] = r
+		d.next = (d.next + 1) % len(d.records)
+		d.full = d.full || d.next == 0
+		d.mu.Unlock()
+	}
+}
+
+// Close ends the subscription of the Dashboard and its streams. The records it already holds are
+// still served.
+func (d *Dashboard) Close() error {
+	d.cancel()
+	<-d.done
+	return nil
+}
+
+// recent returns the records held by the Dashboard, oldest first.
+func (d *Dashboard) recent() []Record {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.full {
+		return append([]Record(nil), d.records[:d.next]...)
+	}
+	return append(append([]Record(nil), d.records[d.next:]...), d.records[:d.next]...)
+}
+
+// dashboardFilter selects the records shown by a Dashboard.
+type dashboardFilter struct {
+	Level     string
+	Component string
+	Query     string
+
+	minLevel  Level
+	auditOnly bool
+}
+
+// newDashboardFilter returns the filter described by the query parameters of req.
+func newDashboardFilter(req *http.Request) (dashboardFilter, error) {
+	q := req.URL.Query()
+	f := dashboardFilter{Level: q.Get("level"), Component: q.Get("component"), Query: q.Get("q")}
+	if f.Level != "" {
+		if err := f.minLevel.UnmarshalText([]byte(f.Level)); err != nil {
+			return f, err
+		}
+		f.auditOnly = f.minLevel == AuditLevel
+	}
+	return f, nil
+}
+
+// match reports whether r passes f.
+func (f dashboardFilter) match(r Record) bool {
+	switch {
+	case f.auditOnly:
+		if r.Level != AuditLevel {
+			return false
+		}
+	// Audit records aren't more severe than errors, so treat them like info records.
+	case r.Level != AuditLevel && r.Level < f.minLevel,
+		r.Level == AuditLevel && f.minLevel > InfoLevel:
+		return false
+	}
+	if f.Component != "" {
+		c := recordComponent(r)
+		if c != f.Component && !strings.HasPrefix(c, f.Component+".") {
+			return false
+		}
+	}
+	return f.Query == "" || strings.Contains(r.Message+" "+formatFields(r.Fields), f.Query)
+}
+
+// recordComponent returns the component path of r, or an empty string if it has none.
+func recordComponent(r Record) string {
+	for _, f := range r.Fields {
+		if f.Key == "component" {
+			return fmt.Sprint(f.Value)
+		}
+	}
+	return ""
+}
+
+// dashboardRow is a record as shown by a Dashboard.
+type dashboardRow struct {
+	Time    string
+	Level   string
+	Caller  string
+	Message string
+	Fields  string
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (d *Dashboard) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f, err := newDashboardFilter(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.URL.Query().Has("stream") || req.Header.Get("Accept") == "text/event-stream" {
+		d.stream(w, req, f)
+		return
+	}
+
+	var rows []dashboardRow
+	for _, r := range d.recent() {
+		if !f.match(r) {
+			continue
+		}
+		rows = append(rows, dashboardRow{
+			Time:    r.Time.Format(time.RFC3339Nano),
+			Level:   r.Level.String(),
+			Caller:  fmt.Sprintf("%s:%d", filepath.Base(r.File), r.Line),
+			Message: r.Message,
+			Fields:  formatFields(r.Fields),
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	dashboardPage.Execute(w, struct {
+		Filter dashboardFilter
+		Rows   []dashboardRow
+	}{f, rows})
+}
+
+// stream sends the records passing f as server-sent events until the client goes away or the
+// Dashboard is closed.
+func (d *Dashboard) stream(w http.ResponseWriter, req *http.Request, f dashboardFilter) {
+	select {
+	case <-d.done:
+		http.Error(w, "dashboard closed", http.StatusServiceUnavailable)
+		return
+	default:
+	}
+	text := req.URL.Query().Get("format") == "text"
+	ch, cancel := d.l.Subscribe(f.match)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+	rc.Flush()
+
+	keepAlive := time.NewTicker(DashboardKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		var err error
+		select {
+		case r := <-ch:
+			var data []byte
+			if text {
+				data, err = r.MarshalText()
+			} else {
+				data, err = r.MarshalJSON()
+			}
+			if err != nil {
+				continue
+			}
+			// Both forms quote line breaks, so every record fits on a single data line.
+			_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+		case <-keepAlive.C:
+			_, err = io.WriteString(w, ": keep-alive\n\n")
+		case <-req.Context().Done():
+			return
+		case <-d.done:
+			return
+		}
+		if err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// dashboardPage is the page served by a Dashboard.
+var dashboardPage = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Recent logs</title>
+<style>
+body { font-family: sans-serif; margin: 1em; }
+table { border-collapse: collapse; font-family: monospace; }
+td { padding: 0.1em 0.5em; vertical-align: top; white-space: pre-wrap; }
+tr.WARNING { color: #a60; }
+tr.ERROR, tr.FATAL { color: #c00; }
+tr.AUDIT { color: #088; }
+</style>
+</head>
+<body>
+<form>
+<label>Level <select name="level">
+<option value=""{{if eq .Filter.Level ""}} selected{{end}}>all</option>
+<option value="warning"{{if eq .Filter.Level "warning"}} selected{{end}}>warning</option>
+<option value="error"{{if eq .Filter.Level "error"}} selected{{end}}>error</option>
+<option value="fatal"{{if eq .Filter.Level "fatal"}} selected{{end}}>fatal</option>
+<option value="audit"{{if eq .Filter.Level "audit"}} selected{{end}}>audit</option>
+</select></label>
+<label>Component <input name="component" value="{{.Filter.Component}}"></label>
+<label>Text <input name="q" value="{{.Filter.Query}}"></label>
+<input type="submit" value="Filter">
+</form>
+<p>{{len .Rows}} records</p>
+<table>
+{{range .Rows}}<tr class="{{.Level}}"><td>{{.Time}}</td><td>{{.Level}}</td><td>{{.Caller}}</td><td>{{.Message}}</td><td>{{.Fields}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,106 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DeltaMode determines whether records carry a "delta" field holding the time elapsed since the
+// previous record, which makes gaps in latency easy to spot in a log.
+type DeltaMode int
+
+const (
+	// DeltaNone writes no delta field. This is the default.
+	DeltaNone DeltaMode = iota
+	// DeltaLogger measures the time since the previous record written by the same logger.
+	DeltaLogger
+	// DeltaGoroutine measures the time since the previous record written by the same goroutine
+	// through any logger in this mode, which keeps concurrent requests apart when each is handled
+	// by a goroutine of its own.
+	DeltaGoroutine
+)
+
+// String returns the name of the mode, such as "logger".
+func (mode DeltaMode) String() string {
+	switch mode {
+	case DeltaNone:
+		return "none"
+	case DeltaLogger:
+		return "logger"
+	case DeltaGoroutine:
+		return "goroutine"
+	}
+	return fmt.Sprintf("DeltaMode(%d)", int(mode))
+}
+
+// maxGoroutineDeltas is the number of goroutines whose last record times are kept before those of
+// goroutines that haven't logged for a while are forgotten.
+const maxGoroutineDeltas = 4096
+
+// goroutineDeltas holds the time of the last record of each goroutine for DeltaGoroutine, keyed
+// by goroutine ID.
+var goroutineDeltas struct {
+	mu   sync.Mutex
+	last map[uint64]time.Time
+}
+
+// SetDeltaMode implements the Logger interface.
+func (l *logger) SetDeltaMode(mode DeltaMode) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.deltaMode = mode
+	l.lastRecord = time.Time{}
+}
+
+// SetDeltaMode is a convenience method that calls defaultLogger.SetDeltaMode(mode)
+func SetDeltaMode(mode DeltaMode) {
+	defaultLogger.SetDeltaMode(mode)
+}
+
+// deltaFields returns the delta field of a record written now by l, if any. It must be called
+// with l.mu held.
+func (l *logger) deltaFields() []Field {
+	if l.deltaMode == DeltaNone {
+		return nil
+	}
+	var last time.Time
+	now := time.Now()
+	switch l.deltaMode {
+	case DeltaLogger:
+		last, l.lastRecord = l.lastRecord, now
+	case DeltaGoroutine:
+		last = swapGoroutineDelta(goroutineID(), now)
+	}
+	if last.IsZero() {
+		return nil
+	}
+	return []Field{{Key: "delta", Value: now.Sub(last)}}
+}
+
+// swapGoroutineDelta records now as the time of the last record of goroutine id, returning the
+// previous one, or the zero time if there is none.
+func swapGoroutineDelta(id uint64, now time.Time) time.Time {
+	goroutineDeltas.mu.Lock()
+	defer goroutineDeltas.mu.Unlock()
+
+	if goroutineDeltas.last == nil {
+		goroutineDeltas.last = map[uint64]time.Time{}
+	}
+	last := goroutineDeltas.last[id]
+	// Goroutines can't be told apart from idle ones once they have exited, so forget those that
+	// haven't logged for a while, and everything if that isn't enough.
+	if _, ok := goroutineDeltas.last[id]; !ok && len(goroutineDeltas.last) >= maxGoroutineDeltas {
+		for g, t := range goroutineDeltas.last {
+			if now.Sub(t) > time.Minute {
+				delete(goroutineDeltas.last, g)
+			}
+		}
+		if len(goroutineDeltas.last) >= maxGoroutineDeltas {
+			clear(goroutineDeltas.last)
+		}
+	}
+	goroutineDeltas.last[id] = now
+	return last
+}
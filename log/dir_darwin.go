@@ -0,0 +1,26 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DefaultLogDir returns the directory Init logs to when LogOptions.LogDir is empty. On macOS this
+// is /var/log for root, and a directory named after the executable in ~/Library/Logs for everyone
+// else.
+func DefaultLogDir() string {
+	if os.Geteuid() == 0 {
+		return "/var/log"
+	}
+	return userLogDir()
+}
+
+// userLogDir returns a log directory for the executable that the current user can write to, or
+// an empty string if there is none.
+func userLogDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, "Library", "Logs", executableName())
+}
@@ -0,0 +1,32 @@
+//go:build !windows && !darwin
+
+package log
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DefaultLogDir returns the directory Init logs to when LogOptions.LogDir is empty. On Linux and
+// other Unix systems this is /var/log for root, and a directory named after the executable in the
+// user's XDG state directory, usually ~/.local/state, for everyone else.
+func DefaultLogDir() string {
+	if os.Geteuid() == 0 {
+		return "/var/log"
+	}
+	return userLogDir()
+}
+
+// userLogDir returns a log directory for the executable that the current user can write to, or
+// an empty string if there is none.
+func userLogDir() string {
+	state := os.Getenv("XDG_STATE_HOME")
+	if state == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		state = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(state, executableName())
+}
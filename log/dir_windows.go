@@ -0,0 +1,26 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DefaultLogDir returns the directory Init logs to when LogOptions.LogDir is empty. On Windows this
+// is a Logs directory for the executable under ProgramData if the process may write there, as
+// services usually can, and under the user's LocalAppData otherwise.
+func DefaultLogDir() string {
+	if programData := os.Getenv("ProgramData"); programData != "" && ValidateLogDir(programData) == nil {
+		return filepath.Join(programData, executableName(), "Logs")
+	}
+	return userLogDir()
+}
+
+// userLogDir returns a log directory for the executable that the current user can write to, or
+// an empty string if there is none.
+func userLogDir() string {
+	localAppData := os.Getenv("LOCALAPPDATA")
+	if localAppData == "" {
+		return ""
+	}
+	return filepath.Join(localAppData, executableName(), "Logs")
+}
@@ -0,0 +1,54 @@
+package log
+
+import "fmt"
+
+// DPanic implements the Logger interface.
+func (l *logger) DPanic(a ...interface{}) {
+	msg := fmt.Sprint(a...)
+	l.logw(0, ErrorLevel, msg, nil)
+	l.dpanic(msg)
+}
+
+// DPanicf implements the Logger interface.
+func (l *logger) DPanicf(format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	l.logw(0, ErrorLevel, msg, nil)
+	l.dpanic(msg)
+}
+
+// DPanicw implements the Logger interface.
+func (l *logger) DPanicw(msg string, keysAndValues ...interface{}) {
+	l.logw(0, ErrorLevel, msg, groupFields(l.group, kvFields(keysAndValues)))
+	l.dpanic(msg)
+}
+
+// dpanic panics with msg if the logger is in development mode.
+func (l *logger) dpanic(msg string) {
+	l.mu.Lock()
+	development := l.development
+	l.mu.Unlock()
+
+	if development {
+		panic(msg)
+	}
+}
+
+// DPanic is a convenience method that calls defaultLogger.DPanic(a...)
+func DPanic(a ...interface{}) {
+	defaultLogger.DPanic(a...)
+}
+
+// DPanicf is a convenience method that calls defaultLogger.DPanicf(format, a...)
+func DPanicf(format string, a ...interface{}) {
+	defaultLogger.DPanicf(format, a...)
+}
+
+// DPanicw is a convenience method that calls defaultLogger.DPanicw(msg, keysAndValues...)
+func DPanicw(msg string, keysAndValues ...interface{}) {
+	defaultLogger.DPanicw(msg, keysAndValues...)
+}
+
+// SetDevelopment is a convenience method that calls defaultLogger.SetDevelopment(development)
+func SetDevelopment(development bool) {
+	defaultLogger.SetDevelopment(development)
+}
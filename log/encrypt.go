@@ -0,0 +1,199 @@
+package log
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+)
+
+const (
+	// maxEncryptedFrame bounds the size of a single encrypted frame accepted by DecryptLog,
+	// protecting it from allocating huge buffers when reading corrupted files.
+	maxEncryptedFrame = 64 << 20
+
+	// encryptedStreamHeader is the size of the header frame that starts each stream of frames
+	// written by an encrypted writer, followed by the random salt of the stream.
+	encryptedStreamHeader = math.MaxUint32
+
+	// encryptedSaltSize is the size of the salt from which the key of a stream is derived. Its
+	// first encryptedIDSize bytes identify the stream in its frames.
+	encryptedSaltSize = 16
+	encryptedIDSize   = 8
+)
+
+// encryptedWriter seals every Write with AES-GCM before passing it on to the underlying writer.
+type encryptedWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	key []byte
+	// salt and AEAD of the stream, or nil until its header is written.
+	salt []byte
+	aead cipher.AEAD
+	// sequence number of the next frame, which is also its nonce.
+	seq uint64
+}
+
+// NewEncryptedWriter returns a writer that encrypts everything written to it with AES-GCM using key,
+// which must be 16, 24 or 32 bytes long, before writing it to w. Use DecryptLog to read the result.
+//
+// The writer starts a stream of frames with a header holding a random salt, from which the key of
+// the stream is derived. Loggers write each log line with a single call to Write, so each line
+// becomes one frame consisting of a 4-byte big-endian length, the 8-byte ID of the stream, an
+// 8-byte big-endian sequence number and the ciphertext, sealed with the sequence number as nonce.
+// The header is written along with the first frame, and frames name their stream, so that the
+// streams of several processes sharing a file may interleave. DecryptLog checks that the frames of
+// each stream are in sequence, so frames that were dropped or reordered are detected. Frames cut
+// off at the end of a stream, or whole streams removed from a file, are not: use a ChainWriter
+// with signed checkpoints where that matters.
+//
+// A stream may hold up to 2^64 frames. Keys of streams are 128-bit random derivations of key, so
+// key should be rotated before it has encrypted 2^48 streams, such as log files, to keep the odds
+// of two streams sharing a key negligible.
+func NewEncryptedWriter(w io.Writer, key []byte) (io.Writer, error) {
+	if _, err := newGCM(key); err != nil {
+		return nil, err
+	}
+	return &encryptedWriter{w: w, key: append([]byte(nil), key...)}, nil
+}
+
+// Write implements io.Writer.
+func (e *encryptedWriter) Write(p []byte) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var header []byte
+	if e.aead == nil {
+		header = make([]byte, 4+encryptedSaltSize)
+		binary.BigEndian.PutUint32(header, encryptedStreamHeader)
+		if _, err := rand.Read(header[4:]); err != nil {
+			return 0, err
+		}
+		aead, err := streamGCM(e.key, header[4:])
+		if err != nil {
+			return 0, err
+		}
+		e.salt, e.aead, e.seq = append([]byte(nil), header[4:]...), aead, 0
+	}
+
+	// A nonce must never be used twice, even if writing the frame fails.
+	seq := e.seq
+	e.seq++
+	frameStart := len(header)
+	frame := append(header, make([]byte, 4+encryptedIDSize+8)...)
+	copy(frame[frameStart+4:], e.salt[:encryptedIDSize])
+	binary.BigEndian.PutUint64(frame[frameStart+4+encryptedIDSize:], seq)
+	frame = e.aead.Seal(frame, streamNonce(e.aead, seq), p, nil)
+	binary.BigEndian.PutUint32(frame[frameStart:], uint32(len(frame)-frameStart-4))
+
+	if _, err := e.w.Write(frame); err != nil {
+		if header != nil {
+			// The header may not have made it, so start a new stream with the next frame.
+			e.salt, e.aead = nil, nil
+		}
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// decryptStream is the state of a stream of frames read by DecryptLog.
+type decryptStream struct {
+	aead cipher.AEAD
+	next uint64
+}
+
+// DecryptLog reads frames written by an encrypted writer from r, decrypts them with key and writes
+// the plaintext to w. It fails on frames that are out of sequence within their stream.
+func DecryptLog(w io.Writer, r io.Reader, key []byte) error {
+	aead, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	minFrame := uint32(encryptedIDSize + 8 + aead.Overhead())
+
+	var size [4]byte
+	streams := map[string]*decryptStream{}
+	for {
+		if _, err := io.ReadFull(r, size[:]); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("reading encrypted frame: %v", err)
+		}
+
+		n := binary.BigEndian.Uint32(size[:])
+		if n == encryptedStreamHeader {
+			salt := make([]byte, encryptedSaltSize)
+			if _, err := io.ReadFull(r, salt); err != nil {
+				return fmt.Errorf("reading encrypted stream header: %v", err)
+			}
+			aead, err := streamGCM(key, salt)
+			if err != nil {
+				return err
+			}
+			streams[string(salt[:encryptedIDSize])] = &decryptStream{aead: aead}
+			continue
+		}
+		if n < minFrame || n > maxEncryptedFrame {
+			return fmt.Errorf("invalid encrypted frame size %d", n)
+		}
+		frame := make([]byte, n)
+		if _, err := io.ReadFull(r, frame); err != nil {
+			return fmt.Errorf("reading encrypted frame: %v", err)
+		}
+
+		id, frame := frame[:encryptedIDSize], frame[encryptedIDSize:]
+		stream := streams[string(id)]
+		if stream == nil {
+			return fmt.Errorf("encrypted frame of unknown stream %x", id)
+		}
+		seq, ciphertext := binary.BigEndian.Uint64(frame), frame[8:]
+		if seq != stream.next {
+			return fmt.Errorf("encrypted frame %d of stream %x out of sequence, expected frame %d", seq,
+				id, stream.next)
+		}
+		plaintext, err := stream.aead.Open(ciphertext[:0], streamNonce(stream.aead, seq), ciphertext, nil)
+		if err != nil {
+			return fmt.Errorf("decrypting frame %d of stream %x: %v", seq, id, err)
+		}
+		stream.next++
+		if _, err := w.Write(plaintext); err != nil {
+			return err
+		}
+	}
+}
+
+// streamGCM returns the AES-GCM AEAD of the stream of frames with salt encrypted with key.
+func streamGCM(key, salt []byte) (cipher.AEAD, error) {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("multilog encrypted log stream"))
+	mac.Write(salt)
+	return newGCM(mac.Sum(nil)[:len(key)])
+}
+
+// streamNonce returns the nonce of the frame with sequence number seq.
+func streamNonce(aead cipher.AEAD, seq uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], seq)
+	return nonce
+}
+
+// newGCM returns an AES-GCM AEAD for key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, errors.New("encryption key must be 16, 24 or 32 bytes long")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,164 @@
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+// encryptedUnits splits a file written by encrypted writers into its stream headers and frames.
+func encryptedUnits(t *testing.T, file []byte) [][]byte {
+	t.Helper()
+	var units [][]byte
+	for len(file) > 0 {
+		n := int(binary.BigEndian.Uint32(file)) + 4
+		if n == encryptedStreamHeader+4 {
+			n = 4 + encryptedSaltSize
+		}
+		if n > len(file) {
+			t.Fatalf("malformed encrypted file")
+		}
+		units = append(units, file[:n])
+		file = file[n:]
+	}
+	return units
+}
+
+// joinUnits concatenates the units at indexes of units.
+func joinUnits(units [][]byte, indexes ...int) []byte {
+	var file []byte
+	for _, i := range indexes {
+		file = append(file, units[i]...)
+	}
+	return file
+}
+
+func TestDecryptLog(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	otherKey := bytes.Repeat([]byte{2}, 32)
+
+	// Two writers sharing a file, as in shared mode: a1 a2 b1 a3 b2.
+	var shared bytes.Buffer
+	a, err := NewEncryptedWriter(&shared, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewEncryptedWriter(&shared, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, write := range []struct {
+		w    io.Writer
+		line string
+	}{{a, "a1\n"}, {a, "a2\n"}, {b, "b1\n"}, {a, "a3\n"}, {b, "b2\n"}} {
+		if _, err := write.w.Write([]byte(write.line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Units: header a, a1, a2, header b, b1, a3, b2.
+	units := encryptedUnits(t, shared.Bytes())
+	if len(units) != 7 {
+		t.Fatalf("shared file has %d headers and frames, want 7", len(units))
+	}
+	tampered := joinUnits(units, 0, 1, 2, 3, 4, 5, 6)
+	tampered[len(tampered)-1] ^= 1
+
+	tests := []struct {
+		name    string
+		file    []byte
+		key     []byte
+		want    string
+		wantErr bool
+	}{
+		{name: "empty", file: nil, key: key},
+		{name: "interleaved streams", file: shared.Bytes(), key: key, want: "a1\na2\nb1\na3\nb2\n"},
+		{name: "wrong key", file: shared.Bytes(), key: otherKey, wantErr: true},
+		{name: "tampered frame", file: tampered, key: key, want: "a1\na2\nb1\na3\n", wantErr: true},
+		{
+			name:    "reordered frames",
+			file:    joinUnits(units, 0, 2, 1, 3, 4, 5, 6),
+			key:     key,
+			wantErr: true,
+		},
+		{
+			name:    "dropped frame",
+			file:    joinUnits(units, 0, 1, 3, 4, 5, 6),
+			key:     key,
+			want:    "a1\nb1\n",
+			wantErr: true,
+		},
+		{
+			name:    "dropped stream header",
+			file:    joinUnits(units, 0, 1, 2, 4),
+			key:     key,
+			want:    "a1\na2\n",
+			wantErr: true,
+		},
+		{
+			name:    "truncated frame",
+			file:    shared.Bytes()[:shared.Len()-1],
+			key:     key,
+			want:    "a1\na2\nb1\na3\n",
+			wantErr: true,
+		},
+		{
+			name: "truncated after a frame",
+			file: joinUnits(units, 0, 1, 2, 3, 4),
+			key:  key,
+			want: "a1\na2\nb1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := DecryptLog(&out, bytes.NewReader(tt.file), tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecryptLog returned error %v, want error %v", err, tt.wantErr)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("DecryptLog wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// failingWriter fails its first write.
+type failingWriter struct {
+	bytes.Buffer
+	failed bool
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if !w.failed {
+		w.failed = true
+		return 0, errTestWrite
+	}
+	return w.Buffer.Write(p)
+}
+
+var errTestWrite = errors.New("write failed")
+
+func TestEncryptedWriterRestartsStreamAfterFailedHeader(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 16)
+	var w failingWriter
+	e, err := NewEncryptedWriter(&w, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Write([]byte("lost\n")); err == nil {
+		t.Fatal("Write didn't return the error of the destination")
+	}
+	if _, err := e.Write([]byte("kept\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := DecryptLog(&out, &w.Buffer, key); err != nil {
+		t.Fatalf("DecryptLog: %v", err)
+	}
+	if got := out.String(); got != "kept\n" {
+		t.Errorf("DecryptLog wrote %q, want %q", got, "kept\n")
+	}
+}
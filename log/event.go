@@ -0,0 +1,83 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// EventField describes a field of an event type.
+type EventField struct {
+	Key      string
+	Required bool
+}
+
+// eventTypes maps the names of the event types defined with DefineEvent to their fields.
+var (
+	eventTypesMu sync.RWMutex
+	eventTypes   = map[string][]EventField{}
+)
+
+// DefineEvent registers the event type name, whose records may only carry the given fields, so that
+// events logged by different parts of a program, or different teams, stay consistent. Defining a
+// type again replaces its fields. Types are typically defined in init functions, next to the code
+// logging them.
+func DefineEvent(name string, fields ...EventField) {
+	eventTypesMu.Lock()
+	defer eventTypesMu.Unlock()
+
+	eventTypes[name] = append([]EventField(nil), fields...)
+}
+
+// validateEvent returns an error if the event type name isn't defined, or fields lack any of its
+// required fields or have fields it doesn't define.
+func validateEvent(name string, fields []Field) error {
+	eventTypesMu.RLock()
+	defined, ok := eventTypes[name]
+	eventTypesMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("undefined event type %q", name)
+	}
+
+	given := map[string]bool{}
+	for _, f := range fields {
+		given[f.Key] = true
+	}
+	var missing []string
+	for _, f := range defined {
+		if f.Required && !given[f.Key] {
+			missing = append(missing, f.Key)
+		}
+		delete(given, f.Key)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("event %q is missing %s", name, strings.Join(missing, ", "))
+	}
+	if len(given) > 0 {
+		unknown := make([]string, 0, len(given))
+		for key := range given {
+			unknown = append(unknown, key)
+		}
+		sort.Strings(unknown)
+		return fmt.Errorf("event %q has undefined fields %s", name, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// Event implements the Logger interface.
+func (l *logger) Event(name string, fields ...Field) error {
+	if err := validateEvent(name, fields); err != nil {
+		return err
+	}
+
+	// Skip logwDepth and Event, plus the package-level function for the default logger.
+	l.logwDepth(l.callerSkip-1, l.defaultVerbosity, InfoLevel, name,
+		append([]Field{{Key: "event", Value: name}}, fields...))
+	return nil
+}
+
+// Event is a convenience method that calls defaultLogger.Event(name, fields...)
+func Event(name string, fields ...Field) error {
+	return defaultLogger.Event(name, fields...)
+}
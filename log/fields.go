@@ -0,0 +1,179 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Field is a key/value pair attached to a log message.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F returns a Field with the given key and value.
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// String returns the field in the "key=value" form used in text logs.
+func (f Field) String() string {
+	var b strings.Builder
+	appendField(&b, f)
+	return b.String()
+}
+
+// appendField writes f to b as key=value, quoting the value if it would otherwise be ambiguous.
+// Hex dumps are written unquoted on the lines following the key.
+func appendField(b *strings.Builder, f Field) {
+	b.WriteString(f.Key)
+	b.WriteByte('=')
+	if d, ok := f.Value.(HexDump); ok {
+		b.WriteByte('\n')
+		b.WriteString(d.String())
+		return
+	}
+	v := fmt.Sprint(f.Value)
+	if v == "" || strings.ContainsAny(v, " \t\r\n\"=") {
+		v = strconv.Quote(v)
+	}
+	b.WriteString(v)
+}
+
+// formatFields returns fields in the text log form "key=value key=value".
+func formatFields(fields []Field) string {
+	var b strings.Builder
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		appendField(&b, f)
+	}
+	return b.String()
+}
+
+// DuplicateKeyPolicy determines which field is kept when a record has several with the same key.
+type DuplicateKeyPolicy int
+
+const (
+	// DuplicateKeysLastWins keeps the value of the last field with a key, at the position of the
+	// first.
+	DuplicateKeysLastWins DuplicateKeyPolicy = iota
+	// DuplicateKeysFirstWins keeps the first field with a key and drops the others.
+	DuplicateKeysFirstWins
+	// DuplicateKeysSuffix keeps all fields, renaming repeated keys to "key_2", "key_3" and so on.
+	DuplicateKeysSuffix
+)
+
+// String returns the name of the policy.
+func (p DuplicateKeyPolicy) String() string {
+	switch p {
+	case DuplicateKeysLastWins:
+		return "last-wins"
+	case DuplicateKeysFirstWins:
+		return "first-wins"
+	case DuplicateKeysSuffix:
+		return "suffix"
+	default:
+		return fmt.Sprintf("DuplicateKeyPolicy(%d)", int(p))
+	}
+}
+
+// dedupeFields returns a copy of fields without duplicate keys, handled according to policy.
+func dedupeFields(fields []Field, policy DuplicateKeyPolicy) []Field {
+	if len(fields) < 2 {
+		return fields
+	}
+	index := make(map[string]int, len(fields))
+	deduped := make([]Field, 0, len(fields))
+	for _, f := range fields {
+		j, dup := index[f.Key]
+		if !dup {
+			index[f.Key] = len(deduped)
+			deduped = append(deduped, f)
+			continue
+		}
+		switch policy {
+		case DuplicateKeysFirstWins:
+		case DuplicateKeysSuffix:
+			for n := 2; ; n++ {
+				key := fmt.Sprintf("%s_%d", f.Key, n)
+				if _, taken := index[key]; !taken {
+					index[key] = len(deduped)
+					deduped = append(deduped, Field{Key: key, Value: f.Value})
+					break
+				}
+			}
+		default:
+			deduped[j].Value = f.Value
+		}
+	}
+	return deduped
+}
+
+// recordFields returns all fields of a record logged by l with the call site fields, from the most
+// general to the most specific, with duplicate keys handled according to l's policy. It must be
+// called with l.mu held.
+func (l *logger) recordFields(fields []Field) []Field {
+	var all []Field
+	if l.global {
+		if fields := globalFields.Load(); fields != nil {
+			all = append(all, *fields...)
+		}
+	}
+	if l.name != "" {
+		all = append(all, Field{Key: "component", Value: l.name})
+	}
+	if l.uptime {
+		all = append(all, uptimeField())
+	}
+	all = append(all, l.deltaFields()...)
+	all = append(all, ambientFields()...)
+	all = append(all, l.pprofLabelFields()...)
+	for _, provide := range l.fieldProviders {
+		all = append(all, provide()...)
+	}
+	all = append(all, l.fields...)
+	all = append(all, fields...)
+	return dedupeFields(all, l.duplicateKeys)
+}
+
+// FieldProvider returns fields describing the state of the program at the time a record is
+// written, such as the number of requests in flight, for context that changes too often to be
+// bound with With. Providers are called with the logger locked, so they must be quick and must not
+// log.
+type FieldProvider func() []Field
+
+// AddFieldProvider implements the Logger interface.
+func (l *logger) AddFieldProvider(provide FieldProvider) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.fieldProviders = append(l.fieldProviders, provide)
+}
+
+// AddFieldProvider is a convenience method that calls defaultLogger.AddFieldProvider(provide)
+func AddFieldProvider(provide FieldProvider) {
+	defaultLogger.AddFieldProvider(provide)
+}
+
+// globalFields holds the fields set with LogOptions.Fields, written with the records of the
+// default logger and the loggers derived from it.
+var globalFields atomic.Pointer[[]Field]
+
+// setGlobalFields sets the global fields to m, sorted by key.
+func setGlobalFields(m map[string]interface{}) {
+	if len(m) == 0 {
+		globalFields.Store(nil)
+		return
+	}
+	fields := make([]Field, 0, len(m))
+	for key, value := range m {
+		fields = append(fields, Field{Key: key, Value: value})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+	globalFields.Store(&fields)
+}
@@ -0,0 +1,188 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultFileName is the log file name template used when LogOptions.FileName is empty. File name
+// templates may contain the following tokens:
+//
+//	{time}      the current Unix time in seconds
+//	{exe}       the name of the executable
+//	{pid}       the process ID
+//	{hostname}  the host name
+//	{seq}       the lowest non-negative integer that gives a file that doesn't exist yet
+const DefaultFileName = "{time}-{exe}-{pid}.log"
+
+// SharedFileName is the log file name template used in shared mode when LogOptions.FileName is
+// empty. It names the same file for every process running the executable.
+const SharedFileName = "{exe}.log"
+
+const (
+	// DefaultFileMode is the permission mode of log files created by Init, before the umask.
+	DefaultFileMode os.FileMode = 0666
+	// DefaultDirMode is the permission mode of log directories created by Init, before the umask.
+	DefaultDirMode os.FileMode = 0755
+)
+
+// fileNameTemplate returns the log file name template configured by opts.
+func fileNameTemplate(opts *LogOptions) string {
+	switch {
+	case opts.FileName != "":
+		return opts.FileName
+	case opts.Shared:
+		return SharedFileName
+	}
+	return DefaultFileName
+}
+
+// logFilePath returns the path of the log file in dir named by template.
+func logFilePath(dir, template string) string {
+	if template == "" {
+		template = DefaultFileName
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	name := strings.NewReplacer(
+		"{time}", strconv.FormatInt(time.Now().Unix(), 10),
+		"{exe}", executableName(),
+		"{pid}", strconv.Itoa(os.Getpid()),
+		"{hostname}", hostname,
+	).Replace(template)
+	if !strings.Contains(name, "{seq}") {
+		return filepath.Join(dir, name)
+	}
+
+	for seq := 0; ; seq++ {
+		path := filepath.Join(dir, strings.ReplaceAll(name, "{seq}", strconv.Itoa(seq)))
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			return path
+		}
+	}
+}
+
+// createLogDir creates dir, and any missing parents, with the mode and ownership configured by
+// opts.
+func createLogDir(dir string, opts *LogOptions) error {
+	mode := opts.DirMode
+	if mode == 0 {
+		mode = DefaultDirMode
+	}
+	if err := os.MkdirAll(dir, mode); err != nil {
+		return err
+	}
+	return setOwner(dir, opts)
+}
+
+// setLogFileMode gives the log file f the exact mode and ownership configured by opts, regardless
+// of the umask.
+func setLogFileMode(f *os.File, opts *LogOptions) error {
+	if opts.FileMode != 0 {
+		if err := f.Chmod(opts.FileMode); err != nil {
+			return err
+		}
+	}
+	return setOwner(f.Name(), opts)
+}
+
+// setOwner changes the owner and group of path to those configured by opts. It does nothing unless
+// the process is running as root.
+func setOwner(path string, opts *LogOptions) error {
+	if (opts.Owner == "" && opts.Group == "") || os.Geteuid() != 0 {
+		return nil
+	}
+
+	uid, gid := -1, -1
+	if opts.Owner != "" {
+		u, err := user.Lookup(opts.Owner)
+		if err != nil {
+			if u, err = user.LookupId(opts.Owner); err != nil {
+				return fmt.Errorf("unknown log file owner %q", opts.Owner)
+			}
+		}
+		uid, _ = strconv.Atoi(u.Uid)
+	}
+	if opts.Group != "" {
+		g, err := user.LookupGroup(opts.Group)
+		if err != nil {
+			if g, err = user.LookupGroupId(opts.Group); err != nil {
+				return fmt.Errorf("unknown log file group %q", opts.Group)
+			}
+		}
+		gid, _ = strconv.Atoi(g.Gid)
+	}
+	return os.Chown(path, uid, gid)
+}
+
+// removeStaleLogs removes the log files in dir that were named by template for this executable and
+// haven't been modified within the retention period, except for the file active. It returns the
+// number of files removed.
+func removeStaleLogs(dir, template string, retention time.Duration, active string) (int, error) {
+	pattern := staleLogPattern(template)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	cutoff := time.Now().Add(-retention)
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if path == active || !pattern.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := os.Lstat(path)
+		if err != nil || !info.Mode().IsRegular() || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// staleLogPattern returns a regular expression matching exactly the names template gives the log
+// files of this executable on this host, whatever the time, process ID and sequence number. Names
+// of other executables sharing the directory, such as foo-worker next to foo, don't match.
+func staleLogPattern(template string) *regexp.Regexp {
+	if template == "" {
+		template = DefaultFileName
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	tokens := regexp.MustCompile(`\{(time|exe|pid|hostname|seq)\}`)
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range tokens.FindAllStringSubmatchIndex(template, -1) {
+		b.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		switch template[loc[2]:loc[3]] {
+		case "exe":
+			b.WriteString(regexp.QuoteMeta(executableName()))
+		case "hostname":
+			b.WriteString(regexp.QuoteMeta(hostname))
+		default:
+			// Times, process IDs and sequence numbers are all decimal integers.
+			b.WriteString(`[0-9]+`)
+		}
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(template[last:]))
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
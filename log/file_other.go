@@ -0,0 +1,29 @@
+//go:build !unix
+
+package log
+
+import (
+	"os"
+	"os/user"
+	"strings"
+)
+
+// noFollow is added to the flags of log files opened for writing from the start. Windows doesn't
+// follow symbolic links on behalf of unprivileged users, so it is empty.
+const noFollow = 0
+
+// privateTempDirName returns the name of the directory of the current user in the temporary
+// directory.
+func privateTempDirName() string {
+	name := "multilog"
+	if u, err := user.Current(); err == nil {
+		name += "-" + strings.ReplaceAll(u.Uid, "\\", "-")
+	}
+	return name
+}
+
+// isPrivateDir reports whether info describes a directory that only the current user can access.
+// The temporary directory of Windows users is already in their profile, so any directory is.
+func isPrivateDir(info os.FileInfo) bool {
+	return info.IsDir()
+}
@@ -0,0 +1,25 @@
+//go:build unix
+
+package log
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// noFollow is added to the flags of log files opened for writing from the start, so that a
+// symbolic link planted where a log file will be created can't redirect the writes.
+const noFollow = syscall.O_NOFOLLOW
+
+// privateTempDirName returns the name of the directory of the current user in the temporary
+// directory.
+func privateTempDirName() string {
+	return "multilog-" + strconv.Itoa(os.Geteuid())
+}
+
+// isPrivateDir reports whether info describes a directory that only the current user can access.
+func isPrivateDir(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && info.IsDir() && int(st.Uid) == os.Geteuid() && info.Mode().Perm()&0077 == 0
+}
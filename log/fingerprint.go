@@ -0,0 +1,37 @@
+package log
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// SetFingerprints implements the Logger interface.
+func (l *logger) SetFingerprints(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.fingerprints = enabled
+}
+
+// SetFingerprints is a convenience method that calls defaultLogger.SetFingerprints(enabled)
+func SetFingerprints(enabled bool) {
+	defaultLogger.SetFingerprints(enabled)
+}
+
+// fingerprintFields returns fields followed by the fingerprint of the logging statement at file and
+// line with template, if the logger adds fingerprints. fields is not modified. It must be called
+// with l.mu held.
+func (l *logger) fingerprintFields(fields []Field, file string, line int, template string) []Field {
+	if !l.fingerprints {
+		return fields
+	}
+	return append(fields[:len(fields):len(fields)], Field{Key: "fingerprint",
+		Value: fingerprint(file, line, template)})
+}
+
+// fingerprint returns the 64-bit FNV-1a hash of a call site and message template, in hexadecimal.
+func fingerprint(file string, line int, template string) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s:%d\x00%s", file, line, template)
+	return fmt.Sprintf("%016x", h.Sum64())
+}
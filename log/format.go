@@ -0,0 +1,184 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Format is the format of the records a logger writes to its writers.
+type Format int
+
+const (
+	// FormatText writes records as text lines such as "[I0042] main.go:12: message key=value".
+	FormatText Format = iota
+	// FormatOTel writes records as JSON objects following the OpenTelemetry log data model and
+	// semantic conventions, with the call site as "code.filepath" and "code.lineno" attributes and
+	// the program as the "service.name" resource attribute, so that OTel-native backends can ingest
+	// them without transform rules.
+	FormatOTel
+	// FormatProtobuf writes records as LogRecord protobuf messages, defined in logrecord.proto,
+	// each preceded by its length as a varint. This is the framing of Java's writeDelimitedTo and
+	// Go's protodelim package.
+	FormatProtobuf
+	// FormatCEF writes records in ArcSight's Common Event Format, configured with SetCEFOptions, so
+	// that they can be sent straight to a SIEM collector.
+	FormatCEF
+	// FormatKlog writes records as klog and glog do, with headers such as
+	// "I1016 09:13:48.021183   12345 main.go:9] ", so that Kubernetes tooling can parse them.
+	// Unlike the other formats, it is used for stderr as well. See also RegisterKlogFlags.
+	FormatKlog
+	// FormatDocker writes records as Docker's json-file logging driver does, as in
+	// {"log":"[I0000] main.go:9: message\n","stream":"stderr","time":"..."}, so that log processors
+	// set up for container runtimes can read the files directly. The stream is "stdout" for
+	// records routed to stdout by SetSplitConsole.
+	FormatDocker
+)
+
+// String returns the name of the format.
+func (f Format) String() string {
+	switch f {
+	case FormatText:
+		return "text"
+	case FormatOTel:
+		return "otel"
+	case FormatProtobuf:
+		return "protobuf"
+	case FormatCEF:
+		return "cef"
+	case FormatKlog:
+		return "klog"
+	case FormatDocker:
+		return "docker"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// SchemaVersion is the version of the schema of structured records, written to each of them as
+// "schema_version". It is incremented whenever fields are renamed, removed or change meaning, so
+// that parsers can handle records of each version deliberately. Adding fields doesn't change the
+// version; parsers should ignore fields they don't know.
+//
+// Versions:
+//
+//	1: the initial schemas of FormatOTel and FormatProtobuf.
+const SchemaVersion = 1
+
+// encode returns r in the logger's structured format, including any terminator or length prefix
+// separating it from the next record. It must be called with l.mu held.
+func (l *logger) encode(r *Record) []byte {
+	msg := truncate(scrub(l.scrubRules, l.scrubbers, redact(l.redactor, r.Message)), l.maxMessageSize)
+	fields := l.cleanFields(r.Fields)
+
+	switch l.format {
+	case FormatOTel:
+		return l.encodeOTel(r, msg, fields)
+	case FormatProtobuf:
+		return l.encodeProtobuf(r, msg, fields)
+	case FormatCEF:
+		return l.encodeCEF(r, msg, fields)
+	case FormatDocker:
+		return l.encodeDocker(r)
+	default:
+		return textLine(l.formatText(r))
+	}
+}
+
+// encodeOTel returns r as an OpenTelemetry JSON log record on its own line, with the cleaned msg
+// and fields.
+func (l *logger) encodeOTel(r *Record, msg string, fields []Field) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `{"schema_version":%d,"timestamp":`, SchemaVersion)
+	appendJSON(&b, r.Time.UTC().Format(time.RFC3339Nano))
+	b.WriteString(`,"severity_text":`)
+	appendJSON(&b, r.Level.String())
+	fmt.Fprintf(&b, `,"severity_number":%d,"body":`, l.severityOf(r).OTel)
+	appendJSON(&b, msg)
+
+	b.WriteString(`,"attributes":{"code.filepath":`)
+	appendJSON(&b, r.File)
+	fmt.Fprintf(&b, `,"code.lineno":%d`, r.Line)
+	for _, f := range fields {
+		b.WriteByte(',')
+		appendJSON(&b, f.Key)
+		b.WriteByte(':')
+		appendJSON(&b, jsonValue(f.Value))
+	}
+	if r.Stacks != nil {
+		b.WriteString(`,"exception.stacktrace":`)
+		appendJSON(&b, string(r.Stacks))
+	}
+
+	b.WriteString(`},"resource":{"service.name":`)
+	appendJSON(&b, l.serviceName)
+	b.WriteString("}}\n")
+	return b.Bytes()
+}
+
+// encodeDocker returns r as a Docker json-file log entry on its own line.
+func (l *logger) encodeDocker(r *Record) []byte {
+	stream := "stderr"
+	if l.splitConsole && (r.Level == InfoLevel || r.Level == AuditLevel) {
+		stream = "stdout"
+	}
+
+	var b bytes.Buffer
+	b.WriteString(`{"log":`)
+	appendJSON(&b, l.formatText(r)+"\n")
+	b.WriteString(`,"stream":`)
+	appendJSON(&b, stream)
+	b.WriteString(`,"time":`)
+	appendJSON(&b, r.Time.UTC().Format(time.RFC3339Nano))
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+// cleanFields returns a copy of fields with the values of sensitive keys redacted and the text of
+// the others scrubbed, as the text format does for whole lines. It must be called with l.mu held.
+func (l *logger) cleanFields(fields []Field) []Field {
+	cleaned := make([]Field, len(fields))
+	for i, f := range fields {
+		switch {
+		case l.redactor != nil && l.redactor.MatchString(f.Key+"=x"):
+			f.Value = RedactedValue
+		case len(l.scrubRules) > 0 || len(l.scrubbers) > 0:
+			// Keep typed values unless scrubbing actually changes them.
+			s := fmt.Sprint(f.Value)
+			if scrubbed := scrub(l.scrubRules, l.scrubbers, s); scrubbed != s {
+				f.Value = scrubbed
+			}
+		}
+		cleaned[i] = f
+	}
+	return cleaned
+}
+
+// jsonValue returns the value to encode as JSON for the field value v. Errors and other values
+// with a String method but no JSON encoding of their own are encoded as their text.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil, json.Marshaler:
+		return v
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	}
+	return v
+}
+
+// appendJSON writes the JSON encoding of v to b, without escaping HTML characters. Values that
+// can't be encoded are written as their text.
+func appendJSON(b *bytes.Buffer, v interface{}) {
+	n := b.Len()
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		b.Truncate(n)
+		enc.Encode(fmt.Sprint(v))
+	}
+	// Drop the newline added by Encode.
+	b.Truncate(b.Len() - 1)
+}
@@ -0,0 +1,37 @@
+package log
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// MaxHexDumpSize is the number of bytes of a HexDump that are rendered. Longer payloads are cut off
+// with a note saying how many bytes were left out.
+var MaxHexDumpSize = 512
+
+// HexDump is binary data rendered as a hex dump with offsets, in the format of the hexdump -C
+// command. Only the first MaxHexDumpSize bytes are rendered.
+type HexDump []byte
+
+// String implements fmt.Stringer.
+func (d HexDump) String() string {
+	data, omitted := []byte(d), 0
+	if MaxHexDumpSize >= 0 && len(data) > MaxHexDumpSize {
+		data, omitted = data[:MaxHexDumpSize], len(data)-MaxHexDumpSize
+	}
+
+	s := strings.TrimSuffix(hex.Dump(data), "\n")
+	if omitted > 0 {
+		s += fmt.Sprintf("\n... %d more bytes", omitted)
+	}
+	return s
+}
+
+// Hex returns a field holding data as a HexDump. In text logs the dump starts on the line after
+// the label, for example
+//
+//	log.Infof("received frame %s", log.Hex("payload", buf))
+func Hex(label string, data []byte) Field {
+	return Field{Key: label, Value: HexDump(data)}
+}
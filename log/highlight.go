@@ -0,0 +1,57 @@
+package log
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Colors for HighlightRule.
+const (
+	ColorRed     = "\x1b[31m"
+	ColorGreen   = "\x1b[32m"
+	ColorYellow  = "\x1b[33m"
+	ColorBlue    = "\x1b[34m"
+	ColorMagenta = "\x1b[35m"
+	ColorCyan    = "\x1b[36m"
+	// ColorBold can be combined with the other colors, as in ColorBold + ColorRed.
+	ColorBold = "\x1b[1m"
+)
+
+// HighlightRule colors records written to stderr by colorful loggers that contain Substring or
+// match Pattern, whichever is set, in Color instead of the color of their level.
+type HighlightRule struct {
+	Pattern   *regexp.Regexp
+	Substring string
+	Color     string
+}
+
+// matches reports whether the record s matches the rule.
+func (r HighlightRule) matches(s string) bool {
+	if r.Pattern != nil {
+		return r.Pattern.MatchString(s)
+	}
+	return r.Substring != "" && strings.Contains(s, r.Substring)
+}
+
+// highlight returns the color of the first of rules matching the record s, or color if none does.
+func highlight(rules []HighlightRule, s, color string) string {
+	for _, rule := range rules {
+		if rule.matches(s) {
+			return rule.Color
+		}
+	}
+	return color
+}
+
+// SetHighlightRules implements the Logger interface.
+func (l *logger) SetHighlightRules(rules ...HighlightRule) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.highlightRules = append([]HighlightRule(nil), rules...)
+}
+
+// SetHighlightRules is a convenience method that calls defaultLogger.SetHighlightRules(rules...)
+func SetHighlightRules(rules ...HighlightRule) {
+	defaultLogger.SetHighlightRules(rules...)
+}
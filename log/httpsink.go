@@ -0,0 +1,202 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of the options shared by the writers posting records to HTTP log collectors.
+const (
+	defaultSinkQueueSize  = 4096
+	defaultSinkBatchBytes = 1 << 20
+	defaultSinkLatency    = 5 * time.Second
+)
+
+// sinkRecord is a record queued by an httpSink, or a flush request if flushed is not nil.
+type sinkRecord struct {
+	p       []byte
+	time    time.Time
+	flushed chan struct{}
+}
+
+// httpSink posts batches of records to an HTTP log collector from a goroutine of its own, so that
+// the collector never holds up the logger. Records are dropped when its queue is full. Batches are
+// posted once they exceed a size, or at the latest after a maximum latency. The writers for
+// specific collectors embed an httpSink and provide the request for each batch.
+type httpSink struct {
+	client     *http.Client
+	request    func(batch []sinkRecord) (*http.Request, error)
+	queue      chan sinkRecord
+	batchBytes int
+	latency    time.Duration
+	dropped    atomic.Int64
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+	err    atomic.Value
+}
+
+// DefaultHTTPTimeout is the timeout of the HTTP client of destinations such as Notifier and
+// NewRelicWriter when none is given, so that an unresponsive server can't hold up logging.
+const DefaultHTTPTimeout = 10 * time.Second
+
+// defaultHTTPClient is the HTTP client of destinations for which none is given.
+var defaultHTTPClient = &http.Client{Timeout: DefaultHTTPTimeout}
+
+// newHTTPSink returns an httpSink posting the requests built by request with client, which
+// defaults to defaultHTTPClient. Zero sizes and latencies are replaced with defaults.
+func newHTTPSink(client *http.Client, request func([]sinkRecord) (*http.Request, error), queueSize,
+	batchBytes int, latency time.Duration) *httpSink {
+	if client == nil {
+		client = defaultHTTPClient
+	}
+	if queueSize <= 0 {
+		queueSize = defaultSinkQueueSize
+	}
+	if batchBytes <= 0 {
+		batchBytes = defaultSinkBatchBytes
+	}
+	if latency <= 0 {
+		latency = defaultSinkLatency
+	}
+	s := &httpSink{
+		client:     client,
+		request:    request,
+		queue:      make(chan sinkRecord, queueSize),
+		batchBytes: batchBytes,
+		latency:    latency,
+		done:       make(chan struct{}),
+	}
+	queues.Store(s, func() int { return len(s.queue) })
+	go s.run()
+	return s
+}
+
+// run collects queued records into batches and posts them until the queue is closed.
+func (s *httpSink) run() {
+	defer close(s.done)
+	var batch []sinkRecord
+	size := 0
+	timer := time.NewTimer(s.latency)
+	timer.Stop()
+	post := func() {
+		timer.Stop()
+		if len(batch) > 0 {
+			if err := s.post(batch); err != nil {
+				s.err.Store(err)
+				reportWriteError(err)
+			}
+		}
+		batch, size = nil, 0
+	}
+
+	for {
+		select {
+		case req, ok := <-s.queue:
+			switch {
+			case !ok:
+				post()
+				return
+			case req.flushed != nil:
+				post()
+				close(req.flushed)
+				continue
+			}
+			if len(batch) == 0 {
+				timer.Reset(s.latency)
+			}
+			batch = append(batch, req)
+			size += len(req.p)
+			if size >= s.batchBytes {
+				post()
+			}
+		case <-timer.C:
+			post()
+		}
+	}
+}
+
+// post sends batch to the collector.
+func (s *httpSink) post(batch []sinkRecord) error {
+	req, err := s.request(batch)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("posting %d log records to %s: %s", len(batch), req.URL.Host, resp.Status)
+	}
+	return nil
+}
+
+// Write queues a copy of the record p, without its trailing newline, to be posted. It never
+// reports errors of the collector; see Err.
+func (s *httpSink) Write(p []byte) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return 0, ErrWriterClosed
+	}
+
+	n := len(p)
+	if n > 0 && p[n-1] == '\n' {
+		n--
+	}
+	select {
+	case s.queue <- sinkRecord{p: append([]byte(nil), p[:n]...), time: time.Now()}:
+	default:
+		s.dropped.Add(1)
+		reportDropped("queue", 1)
+	}
+	return len(p), nil
+}
+
+// Flush posts all records queued before the call.
+func (s *httpSink) Flush() error {
+	s.mu.RLock()
+	if s.closed {
+		s.mu.RUnlock()
+		return nil
+	}
+	flushed := make(chan struct{})
+	s.queue <- sinkRecord{flushed: flushed}
+	s.mu.RUnlock()
+
+	<-flushed
+	return s.Err()
+}
+
+// Close posts all queued records and stops the writer.
+func (s *httpSink) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	<-s.done
+	queues.Delete(s)
+	return s.Err()
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (s *httpSink) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Err returns the last error posting records, if any.
+func (s *httpSink) Err() error {
+	err, _ := s.err.Load().(error)
+	return err
+}
@@ -0,0 +1,368 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Init initializes the logging package. If the log file can't be opened in the configured log
+// directory, Init falls back to the user's log directory or a private directory in the temporary
+// directory, unless LogOptions.NoFallback is set, and logs a warning saying where logs went. If
+// that fails as well, a warning is logged and logging continues to stderr only. Use InitE to
+// handle such problems instead.
+//
+// Init may be called again to change the configuration. The log file opened by the previous call
+// is closed, and the default logger keeps counting records from where it was unless
+// LogOptions.ResetCounters is set.
+//
+// Init upgrades the default logger in place, so that libraries may configure it before main calls
+// Init. Every setting is reset to that of opts, but the writers, fatal hooks, field providers and
+// scrubbers added to the default logger with AddWriter and friends are kept, as are its
+// subscriptions and the toggles of SetStderrEnabled and SetFileEnabled.
+func Init(opts *LogOptions) {
+	logName, err := initialize(opts)
+	if err != nil {
+		Warningf("unable to open default log file: %v", err)
+	}
+	if opts.HijackStderr {
+		if err := HijackStderr(); err != nil {
+			Warningf("unable to hijack stderr: %v", err)
+		}
+	}
+	logStart(opts)
+	logCommandLine(opts)
+	logBanner(opts, logName)
+}
+
+// InitE is like Init, but returns an error instead of logging a warning if no log directory is
+// usable. The default logger is initialized either way, logging to stderr only if the log file
+// couldn't be opened, so callers can decide for themselves whether to proceed.
+func InitE(opts *LogOptions) error {
+	logName, err := initialize(opts)
+	if opts.HijackStderr {
+		err = errors.Join(err, HijackStderr())
+	}
+	logStart(opts)
+	logCommandLine(opts)
+	logBanner(opts, logName)
+	return err
+}
+
+// ErrLogFileLocked is returned by InitE when LogOptions.Lock is set and another process holds the
+// lock on the log file.
+var ErrLogFileLocked = errors.New("log file is locked by another process")
+
+// initialize sets up the default logger for Init and InitE. It returns the name of the opened log
+// file, or an error explaining why no log file could be opened.
+func initialize(opts *LogOptions) (string, error) {
+	// Errors of the previous configuration are no concern of the new one.
+	closeLogFiles()
+	markStarted()
+
+	if opts.Container.enabled() {
+		configureDefaultLogger(opts, []io.Writer{os.Stdout})
+		return "", nil
+	}
+	if opts.StderrOnly {
+		configureDefaultLogger(opts, nil)
+		return "", nil
+	}
+
+	logBase = opts.LogDir
+	if logBase == "" {
+		logBase = DefaultLogDir()
+		// Per-user default directories usually need to be created on first use.
+		createLogDir(logBase, opts)
+	}
+
+	var writers []io.Writer
+	logName, file, err := openLogFile(logBase, opts)
+	configuredDir, dirErr := logBase, err
+	// Another instance holding the lock must not be dodged by logging somewhere else.
+	if err != nil && !opts.NoFallback && !errors.Is(err, ErrLogFileLocked) {
+		for _, dir := range fallbackLogDirs(opts) {
+			if name, f, ferr := openLogFile(dir, opts); ferr == nil {
+				logBase, logName, file, err = dir, name, f, nil
+				break
+			}
+		}
+	}
+	if err == nil {
+		defaultLogFile = file
+		logFiles = append(logFiles, file)
+		writers = append(writers, file)
+	}
+
+	configureDefaultLogger(opts, writers)
+
+	if err == nil && dirErr != nil {
+		Warningf("unable to use log directory %s (%v), logging to %s instead", configuredDir, dirErr, logName)
+	}
+	if err == nil && opts.Retention > 0 {
+		n, cerr := removeStaleLogs(logBase, fileNameTemplate(opts), opts.Retention, logName)
+		if cerr != nil {
+			Warningf("unable to remove stale log files: %v", cerr)
+		}
+		if n > 0 {
+			Infof("removed %d log files older than %v", n, opts.Retention)
+		}
+	}
+	return logName, err
+}
+
+// newDefaultLogger returns the default logger in effect until Init is called, which logs to stderr
+// only so that libraries and programs that never call Init can log.
+func newDefaultLogger() *logger {
+	l := NewLogger(true, false, false).(*logger)
+	l.global = true
+	// The default logger skips an extra stack frame when it logs to account for the package
+	// convenience functions.
+	l.callerSkip++
+	return l
+}
+
+// configureDefaultLogger reconfigures the default logger in place to write to writers, configured
+// as described by opts. Loggers created from the default logger before, such as named children,
+// keep inheriting from it.
+func configureDefaultLogger(opts *LogOptions, writers []io.Writer) {
+	l := newConfiguredLogger(opts, writers)
+	l.global = true
+	// The default logger skips an extra stack frame when it logs to account for the package
+	// convenience functions.
+	l.callerSkip++
+	setGlobalFields(opts.Fields)
+
+	defaultLogger.mu.Lock()
+	l.inheritAdditions(&defaultLogger.loggerConfig)
+	initInstalled.writers = writers
+	initInstalled.fieldProviders = len(opts.FieldProviders)
+	initInstalled.scrubbers = len(opts.Scrubbers)
+	defaultLogger.loggerConfig = l.loggerConfig
+	if opts.ResetCounters {
+		defaultLogger.count = map[Level]int64{}
+	}
+	defaultLogger.quotaWindows = nil
+	defaultLogger.mu.Unlock()
+}
+
+// initInstalled describes what Init installed in the default logger from LogOptions, as opposed to
+// what the program added itself: the writers, and the number of leading field providers and
+// scrubbers.
+var initInstalled struct {
+	writers        []io.Writer
+	fieldProviders int
+	scrubbers      int
+}
+
+// inheritAdditions gives c, the new configuration of the default logger, the state the program
+// added to old, its previous configuration, which Init and Shutdown must not throw away. It must be
+// called with defaultLogger.mu held.
+func (c *loggerConfig) inheritAdditions(old *loggerConfig) {
+	// Subscribers keep observing the default logger through reconfigurations.
+	c.subscriptions = old.subscriptions
+	for _, w := range old.writers {
+		if !slices.ContainsFunc(initInstalled.writers, func(installed io.Writer) bool {
+			return sameWriter(installed, w)
+		}) {
+			c.writers = append(c.writers, w)
+		}
+	}
+	c.fatalHooks = append(c.fatalHooks, old.fatalHooks...)
+	providers := old.fieldProviders[min(initInstalled.fieldProviders, len(old.fieldProviders)):]
+	c.fieldProviders = append(c.fieldProviders, providers...)
+	scrubbers := old.scrubbers[min(initInstalled.scrubbers, len(old.scrubbers)):]
+	c.scrubbers = append(c.scrubbers, scrubbers...)
+	c.stderrDisabled = old.stderrDisabled
+	c.filesDisabled = old.filesDisabled
+}
+
+// newConfiguredLogger returns a logger writing to stderr and writers, configured as described by
+// the settings of opts that apply to loggers rather than to log files.
+func newConfiguredLogger(opts *LogOptions, writers []io.Writer) *logger {
+	l := NewLogger(true, opts.Colorful, opts.Timestamp, writers...).(*logger)
+	// The initial verbosity isn't a change worth recording, so bypass SetVerbosity.
+	l.verbosity = opts.Verbosity
+	l.SetFatalBehavior(opts.FatalBehavior)
+	if opts.FatalHandler != nil {
+		l.SetFatalHandler(opts.FatalHandler)
+	}
+	switch {
+	case opts.NoFatalTimeout:
+		l.SetFatalTimeout(0)
+	case opts.FatalTimeout > 0:
+		l.SetFatalTimeout(opts.FatalTimeout)
+	}
+	if opts.FatalExitCode != 0 {
+		l.SetFatalExitCode(opts.FatalExitCode)
+	}
+	if opts.FatalHookTimeout > 0 {
+		l.SetFatalHookTimeout(opts.FatalHookTimeout)
+	}
+	l.SetFatalStacks(opts.FatalStacks)
+	l.SetCrashReports(opts.CrashReportDir, opts.CrashReportRecords)
+	l.SetRecoverFatal(opts.RecoverFatal)
+	l.SetProduction(opts.Production)
+	l.SetDevelopment(opts.Development)
+	l.SetRedactKeys(opts.RedactKeys...)
+	l.SetScrubRules(opts.ScrubRules...)
+	for _, scrubber := range opts.Scrubbers {
+		l.AddScrubber(scrubber)
+	}
+	for _, provide := range opts.FieldProviders {
+		l.AddFieldProvider(provide)
+	}
+	l.SetAuditOutput(opts.AuditWriters...)
+	l.SetMaxMessageSize(opts.MaxMessageSize)
+	l.SetSanitizeMode(opts.Sanitize)
+	l.SetMultilineMarkers(opts.MultilineMarkers)
+	l.SetFingerprints(opts.Fingerprints)
+	l.SetPprofLabels(opts.PprofLabels...)
+	l.SetUptime(opts.Uptime)
+	l.SetDeltaMode(opts.DeltaMode)
+	l.SetQuotas(opts.Quotas...)
+	l.SetMetricInterval(opts.MetricInterval)
+	l.SetDuplicateKeyPolicy(opts.DuplicateKeys)
+	l.SetFormat(opts.Format)
+	if opts.Container.enabled() {
+		// Containers collect structured records from stdout, which replaces stderr.
+		l.logToStderr = false
+		if opts.Format == FormatText {
+			l.format = FormatOTel
+		}
+	}
+	l.SetTimestampGranularity(opts.TimestampGranularity)
+	if opts.ServiceName != "" {
+		l.SetServiceName(opts.ServiceName)
+	}
+	l.SetCEFOptions(opts.CEF)
+	l.SetSeverityMapping(opts.Severity)
+	l.SetHighlightRules(opts.HighlightRules...)
+	l.SetSplitConsole(opts.SplitConsole)
+	return l
+}
+
+// fallbackLogDirs returns the directories to try, in order, when the configured log directory
+// can't be used: the user's log directory for the executable, and a directory private to the user
+// in the temporary directory.
+func fallbackLogDirs(opts *LogOptions) []string {
+	var dirs []string
+	if dir := userLogDir(); dir != "" {
+		if err := createLogDir(dir, opts); err == nil {
+			dirs = append(dirs, dir)
+		}
+	}
+	if dir, err := privateTempDir(); err == nil {
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// privateTempDir returns a directory in the temporary directory that only the current user can
+// access, creating it if needed. The temporary directory is shared by all users, so a directory
+// that already exists is used only if the current user owns it and nobody else can access it.
+func privateTempDir() (string, error) {
+	dir := filepath.Join(os.TempDir(), privateTempDirName())
+	if err := os.Mkdir(dir, 0700); err != nil && !errors.Is(err, os.ErrExist) {
+		return "", err
+	}
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !isPrivateDir(info) {
+		return "", fmt.Errorf("%s is not a private directory", dir)
+	}
+	return dir, nil
+}
+
+// executableName returns the name of the running executable, without any ".exe" extension.
+func executableName() string {
+	return strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+}
+
+// LogFile returns the path of the log file opened by Init, or an empty string if there is none.
+// It may differ from the configured log directory if Init had to fall back to another one.
+func LogFile() string {
+	if defaultLogFile == nil {
+		return ""
+	}
+	return defaultLogFile.Name()
+}
+
+// logBanner writes the startup banner for Init or InitE if opts asks for one.
+func logBanner(opts *LogOptions, logName string) {
+	if opts.Banner {
+		// Skip logwDepth, logBanner and Init to attribute the banner to Init's caller.
+		defaultLogger.logwDepth(3, 0, InfoLevel, "logging configured", configFields(opts, logName))
+	}
+}
+
+// openLogFile creates a new log file for this process in dir as configured by opts.
+func openLogFile(dir string, opts *LogOptions) (string, *os.File, error) {
+	if err := ValidateLogDir(dir); err != nil {
+		return "", nil, err
+	}
+
+	name := logFilePath(dir, fileNameTemplate(opts))
+	if opts.Lock && opts.Shared {
+		return "", nil, errors.New("log file locking can't be combined with shared mode")
+	}
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC | noFollow
+	switch {
+	case opts.Append || opts.Shared:
+		flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	case opts.Lock:
+		// Truncate only once the lock is held, so a locked file isn't clobbered.
+		flag = os.O_WRONLY | os.O_CREATE | noFollow
+	}
+	mode := opts.FileMode
+	if mode == 0 {
+		mode = DefaultFileMode
+	}
+	file, err := os.OpenFile(name, flag, mode)
+	if err != nil {
+		return "", nil, err
+	}
+	if opts.Lock {
+		if err := lockFile(file); err != nil {
+			file.Close()
+			return "", nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if !opts.Append {
+			if err := file.Truncate(0); err != nil {
+				file.Close()
+				return "", nil, err
+			}
+		}
+	}
+	if err := setLogFileMode(file, opts); err != nil {
+		file.Close()
+		return "", nil, err
+	}
+	return name, file, nil
+}
+
+// ValidateLogDir returns an error if dir can't be used as a log directory because it doesn't
+// exist, isn't a directory or isn't writable.
+func ValidateLogDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("invalid log directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("invalid log directory: %s is not a directory", dir)
+	}
+
+	f, err := os.CreateTemp(dir, ".multilog-*")
+	if err != nil {
+		return fmt.Errorf("log directory %s is not writable: %w", dir, err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return nil
+}
@@ -0,0 +1,60 @@
+package log
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// klogSeverity maps levels to the severity letters of klog headers.
+var klogSeverity = map[Level]string{
+	InfoLevel:    "I",
+	WarningLevel: "W",
+	ErrorLevel:   "E",
+	FatalLevel:   "F",
+	AuditLevel:   "I",
+}
+
+// pid is the process ID written to klog headers in place of the thread ID.
+var pid = os.Getpid()
+
+// formatKlog returns r as a klog line with the already cleaned message and fields body, as in
+// "I1016 09:13:48.021183   12345 main.go:9] body".
+func (l *logger) formatKlog(r *Record, body string) string {
+	s := fmt.Sprintf("%s%s %7d %s:%d] %s", klogSeverity[r.Level], r.Time.Format("0102 15:04:05.000000"), pid,
+		filepath.Base(r.File), r.Line, body)
+	if r.Stacks != nil {
+		s = fmt.Sprintf("%s\n%s", s, r.Stacks)
+	}
+	return s
+}
+
+// RegisterKlogFlags registers the flags of klog and glog that have a counterpart in opts on fs, so
+// that programs moving from klog keep their command lines:
+//
+//	-v                verbosity
+//	-log_dir          LogDir
+//	-log_file         LogDir and FileName
+//	-logtostderr      StderrOnly
+//	-alsologtostderr  accepted for compatibility; Init always logs to stderr outside container mode
+//
+// The flags must be parsed before opts is passed to Init. Set opts.Format to FormatKlog for klog's
+// output format as well.
+func RegisterKlogFlags(fs *flag.FlagSet, opts *LogOptions) {
+	fs.IntVar(&opts.Verbosity, "v", opts.Verbosity, "number for the log level verbosity")
+	fs.StringVar(&opts.LogDir, "log_dir", opts.LogDir, "if non-empty, write log files in this directory")
+	fs.Func("log_file", "if non-empty, use this log file", func(s string) error {
+		opts.LogDir, opts.FileName = filepath.Split(s)
+		if opts.LogDir == "" {
+			opts.LogDir = "."
+		}
+		return nil
+	})
+	fs.BoolVar(&opts.StderrOnly, "logtostderr", opts.StderrOnly, "log to standard error instead of files")
+	fs.BoolFunc("alsologtostderr", "log to standard error as well as files (always on)", func(s string) error {
+		_, err := strconv.ParseBool(s)
+		return err
+	})
+}
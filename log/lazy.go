@@ -0,0 +1,25 @@
+package log
+
+import "fmt"
+
+// LazyValue is a logging operand whose value is only computed when the message containing it is
+// actually formatted. Messages are formatted after the verbosity check, so a LazyValue passed to a
+// suppressed VInfo call costs nothing beyond the closure itself.
+type LazyValue func() interface{}
+
+// Lazy wraps f so that it's only called if a message using the result is written, for example
+//
+//	log.VInfof(3, "request body: %s", log.Lazy(func() interface{} { return dump(req) }))
+func Lazy(f func() interface{}) LazyValue {
+	return LazyValue(f)
+}
+
+// Format implements fmt.Formatter, formatting the computed value with the same verb and flags.
+func (v LazyValue) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), v())
+}
+
+// String implements fmt.Stringer.
+func (v LazyValue) String() string {
+	return fmt.Sprint(v())
+}
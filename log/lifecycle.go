@@ -0,0 +1,140 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// lifecycle holds the time Init was called with LogOptions.Lifecycle set, so that the exit record
+// can report the uptime of the process. It is nil when lifecycle records are disabled.
+var lifecycle atomic.Pointer[time.Time]
+
+// logStart logs the record of the process starting for Init, configured with opts, if lifecycle
+// records are enabled.
+func logStart(opts *LogOptions) {
+	if !opts.Lifecycle {
+		lifecycle.Store(nil)
+		return
+	}
+	start := time.Now()
+	lifecycle.Store(&start)
+
+	fields := []Field{
+		{Key: "version", Value: serviceVersion()},
+		{Key: "pid", Value: os.Getpid()},
+		// The name of the log file changes from run to run, and is left out of the hash.
+		{Key: "config_hash", Value: fingerprint(formatFields(configFields(opts, "")), 0, "")},
+	}
+	// Skip logwDepth, logStart and Init to attribute the record to Init's caller.
+	defaultLogger.logwDepth(3, math.MinInt, InfoLevel, "service starting", fields)
+}
+
+// logExit logs the record of the process exiting for reason through l, if lifecycle records are
+// enabled, attributing it to the caller skip stack frames up, where a skip of 0 identifies logExit
+// itself.
+func (l *logger) logExit(skip int, reason string) {
+	start := lifecycle.Load()
+	if start == nil {
+		return
+	}
+	l.logwDepth(skip+1, math.MinInt, InfoLevel, "service exiting", []Field{
+		{Key: "uptime", Value: time.Since(*start).Round(time.Millisecond)},
+		{Key: "reason", Value: reason},
+	})
+}
+
+// serviceVersion returns the version of the main module of the running binary, if known.
+func serviceVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "unknown"
+}
+
+// Close ends logging to the log file opened by Init: it logs a "service exiting" record if
+// LogOptions.Lifecycle was set, flushes the default logger, and closes the log file. The default
+// logger keeps logging to stderr and its other destinations afterwards. Close is typically
+// deferred in main.
+func Close() error {
+	defaultLogger.logExit(2, "closed")
+	return closeLogFiles()
+}
+
+// Shutdown tears down the state of the package set up by Init and its options, so that test
+// suites and programs embedding others can initialize the package several times without leaking
+// files or goroutines. It closes the log like Close, stops aggregating metrics, restores a hijacked
+// stderr and resets the settings of the default logger, leaving the package as if Init had never
+// been called. Like Init, it keeps the writers, hooks and other additions made to the default
+// logger by the program, but drops those installed from LogOptions. Loggers cloned from the
+// default logger keep their configuration, but may be left writing to closed files.
+func Shutdown() error {
+	defaultLogger.logExit(2, "shutdown")
+	err := errors.Join(closeLogFiles(), RestoreStderr())
+
+	reset := newDefaultLogger()
+	defaultLogger.mu.Lock()
+	reset.inheritAdditions(&defaultLogger.loggerConfig)
+	initInstalled.writers = nil
+	initInstalled.fieldProviders, initInstalled.scrubbers = 0, 0
+	defaultLogger.loggerConfig = reset.loggerConfig
+	defaultLogger.count = map[Level]int64{}
+	defaultLogger.quotaWindows = nil
+	defaultLogger.mu.Unlock()
+	logBase = ""
+	globalFields.Store(nil)
+	lifecycle.Store(nil)
+	return err
+}
+
+// closeLogFiles logs any aggregated metrics, flushes the default logger and closes the log files
+// opened by Init, removing them from the default logger's destinations.
+func closeLogFiles() error {
+	defaultLogger.SetMetricInterval(0)
+	errs := []error{defaultLogger.Flush()}
+	for _, f := range logFiles {
+		defaultLogger.RemoveWriter(f)
+		errs = append(errs, f.Close())
+	}
+	logFiles, defaultLogFile = nil, nil
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("closing log: %w", err)
+	}
+	return nil
+}
+
+// logCommandLine logs the command line of the process and the environment variables allowed by
+// opts.LogEnv for Init, if opts.LogCommandLine is set.
+func logCommandLine(opts *LogOptions) {
+	if !opts.LogCommandLine {
+		return
+	}
+	fields := []Field{{Key: "args", Value: os.Args}}
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		if envAllowed(opts.LogEnv, key) {
+			fields = append(fields, Field{Key: "env." + key, Value: value})
+		}
+	}
+	// Skip logwDepth, logCommandLine and Init to attribute the record to Init's caller.
+	defaultLogger.logwDepth(3, math.MinInt, InfoLevel, "command line", fields)
+}
+
+// envAllowed reports whether the environment variable key matches one of the patterns of allowed,
+// which are names, or prefixes followed by "*".
+func envAllowed(allowed []string, key string) bool {
+	for _, pattern := range allowed {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(key, prefix) {
+			return true
+		}
+		if pattern == key {
+			return true
+		}
+	}
+	return false
+}
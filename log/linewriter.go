@@ -0,0 +1,68 @@
+package log
+
+import (
+	"bytes"
+	"sync"
+)
+
+// LineWriter logs each line written to it as a record, for libraries and tools that only write
+// their output to an io.Writer. Partial lines are buffered until they are complete or the writer is
+// flushed, and empty lines are dropped. Records report the caller of Write as their location.
+type LineWriter struct {
+	logLine func(line string)
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// NewLineWriter returns a LineWriter logging lines through l as records of level. Fatal and audit
+// lines are logged as errors and info records respectively, since writing output must not exit the
+// program or forge audit events.
+func NewLineWriter(l Logger, level Level) *LineWriter {
+	c := l.Clone(WithCallerSkip(3))
+	switch level {
+	case WarningLevel:
+		return &LineWriter{logLine: func(line string) { c.Warning(line) }}
+	case ErrorLevel, FatalLevel:
+		return &LineWriter{logLine: func(line string) { c.Error(line) }}
+	default:
+		return &LineWriter{logLine: func(line string) { c.Info(line) }}
+	}
+}
+
+// Write logs the complete lines in p, and buffers what remains.
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	rest := w.buf
+	for {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			break
+		}
+		w.log(rest[:i])
+		rest = rest[i+1:]
+	}
+	w.buf = append(w.buf[:0], rest...)
+	return len(p), nil
+}
+
+// Flush logs the buffered partial line, if any.
+func (w *LineWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.log(w.buf)
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// log logs line, without any trailing carriage return, unless it is empty.
+func (w *LineWriter) log(line []byte) {
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	if len(line) > 0 {
+		w.logLine(string(line))
+	}
+}
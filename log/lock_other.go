@@ -0,0 +1,14 @@
+//go:build !unix
+
+package log
+
+import (
+	"errors"
+	"os"
+)
+
+// lockFile takes an exclusive advisory lock on f without blocking, which isn't supported on this
+// platform.
+func lockFile(f *os.File) error {
+	return errors.New("log file locking is not supported on this platform")
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package log
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive advisory lock on f without blocking. The lock is held until f is
+// closed.
+func lockFile(f *os.File) error {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return ErrLogFileLocked
+	}
+	return err
+}
@@ -2,45 +2,97 @@
 package log
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
-	"path"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
+// Level is the severity of a log message.
+type Level int
+
 const (
-	logInfo int = iota
-	logWarning
-	logError
-	logFatal
+	// InfoLevel is the level of informational messages.
+	InfoLevel Level = iota
+	// WarningLevel is the level of warnings.
+	WarningLevel
+	// ErrorLevel is the level of errors.
+	ErrorLevel
+	// FatalLevel is the level of fatal errors, after which the logger stops the program.
+	FatalLevel
+	// AuditLevel is the level of audit records written by Audit.
+	AuditLevel
+)
 
+// String returns the name of the level, such as "INFO".
+func (level Level) String() string {
+	switch level {
+	case InfoLevel:
+		return "INFO"
+	case WarningLevel:
+		return "WARNING"
+	case ErrorLevel:
+		return "ERROR"
+	case FatalLevel:
+		return "FATAL"
+	case AuditLevel:
+		return "AUDIT"
+	}
+	return fmt.Sprintf("Level(%d)", int(level))
+}
+
+const (
 	defaultColor = "\x1b[0m"
 	infoColor    = "\x1b[32m"
 	warningColor = "\x1b[33m"
 	errorColor   = "\x1b[31m"
+
+	// DefaultFatalTimeout is how long a logger waits for a fatal message to be written before
+	// giving up and panicking.
+	DefaultFatalTimeout = time.Second / 2
+
+	// DefaultFatalHookTimeout is how long a logger waits for its fatal hooks to finish before
+	// carrying out its fatal behavior.
+	DefaultFatalHookTimeout = 5 * time.Second
+
+	// DefaultFatalFlushTimeout is how long a logger waits, after writing a fatal message and
+	// before exiting, for destinations that buffer records, such as network sinks, to flush them.
+	DefaultFatalFlushTimeout = 5 * time.Second
+
+	// DefaultFatalExitCode is the exit code used by loggers configured with FatalExit.
+	DefaultFatalExitCode = 1
 )
 
 var (
-	logColor = map[int]string{
-		logInfo:    infoColor,
-		logWarning: warningColor,
-		logError:   errorColor,
-		logFatal:   errorColor,
+	logColor = map[Level]string{
+		InfoLevel:    infoColor,
+		WarningLevel: warningColor,
+		ErrorLevel:   errorColor,
+		FatalLevel:   errorColor,
+		AuditLevel:   defaultColor,
 	}
 
-	logPrefix = map[int]string{
-		logInfo:    "I",
-		logWarning: "W",
-		logError:   "E",
-		logFatal:   "FATAL",
+	logPrefix = map[Level]string{
+		InfoLevel:    "I",
+		WarningLevel: "W",
+		ErrorLevel:   "E",
+		FatalLevel:   "FATAL",
+		AuditLevel:   "A",
 	}
 
-	defaultLogger  *logger
-	logBase        = "/var/log"
+	defaultLogger  = newDefaultLogger()
+	logBase        string
 	defaultLogFile *os.File
 	logFiles       []*os.File
 )
@@ -48,34 +100,194 @@ var (
 type LogOptions struct {
 	Verbosity int
 	Colorful  bool
+	// LogDir is the directory in which Init creates the log file. If empty, DefaultLogDir is used.
 	LogDir    string
 	Timestamp bool
+	// TimestampGranularity makes the default logger format timestamps at most once per
+	// granularity. See SetTimestampGranularity.
+	TimestampGranularity time.Duration
+
+	// FileName is a template for the name of the log file created by Init. If empty,
+	// DefaultFileName is used, which also documents the tokens templates may contain.
+	FileName string
+	// Append opens an existing log file in append mode instead of truncating it. Combined with a
+	// fixed FileName such as "service.log", this makes every run of a program log to the same file.
+	Append bool
+	// Shared lets several processes, such as prefork workers, log to the same file safely. The
+	// file is opened in append mode, so every record is written atomically at the end of the file
+	// by the single write the logger makes for it. If FileName is empty, SharedFileName is used.
+	Shared bool
+	// Lock takes an exclusive advisory lock on the log file, so that two instances of a program
+	// configured with the same fixed FileName can't write to the same file. If another process
+	// holds the lock, InitE fails with ErrLogFileLocked instead of falling back to another
+	// directory. Lock can't be combined with Shared.
+	Lock bool
+	// Retention makes Init remove this executable's log files, as named by FileName, that haven't
+	// been written to for longer than the given duration. Zero disables the cleanup.
+	Retention time.Duration
+	// FileMode is the permission mode of the log file, applied regardless of the umask. Zero means
+	// DefaultFileMode, subject to the umask.
+	FileMode os.FileMode
+	// DirMode is the permission mode of log directories created by Init. Zero means
+	// DefaultDirMode.
+	DirMode os.FileMode
+	// Owner and Group, as names or numeric IDs, are given ownership of the log file and any log
+	// directories created by Init when running as root.
+	Owner string
+	Group string
+
+	// FatalBehavior determines what the default logger does after writing a fatal message.
+	FatalBehavior FatalBehavior
+	// FatalHandler is called after writing a fatal message when FatalBehavior is FatalCustom.
+	FatalHandler func(msg string)
+	// FatalTimeout is how long to wait for a fatal message to be written before panicking. Zero
+	// means DefaultFatalTimeout.
+	FatalTimeout time.Duration
+	// NoFatalTimeout disables the fatal write timeout entirely.
+	NoFatalTimeout bool
+	// FatalExitCode is the exit code used when FatalBehavior is FatalExit. Zero means
+	// DefaultFatalExitCode.
+	FatalExitCode int
+	// FatalHookTimeout is how long to wait for fatal hooks to finish. Zero means
+	// DefaultFatalHookTimeout.
+	FatalHookTimeout time.Duration
+	// FatalStacks appends the stacks of all goroutines to fatal messages.
+	FatalStacks bool
+	// CrashReportDir is the directory where a crash report file is written for each fatal record,
+	// holding the last CrashReportRecords records. See SetCrashReports.
+	CrashReportDir     string
+	CrashReportRecords int
+
+	// RecoverFatal makes Go and Recover log recovered panics as fatal messages instead of errors.
+	RecoverFatal bool
+
+	// Production puts the default logger in production mode, where failed assertions are logged as
+	// errors instead of fatal messages.
+	Production bool
+
+	// Development puts the default logger in development mode, where DPanic panics after logging.
+	Development bool
+
+	// RedactKeys lists key names whose values are masked in messages written by the default logger.
+	RedactKeys []string
+	// ScrubRules are applied to every message written by the default logger.
+	ScrubRules []ScrubRule
+	// Scrubbers are run on every message written by the default logger after its scrub rules.
+	Scrubbers []Scrubber
+	// FieldProviders add fields to every record of the default logger. See AddFieldProvider.
+	FieldProviders []FieldProvider
+
+	// AuditWriters receive the default logger's audit records instead of its regular destinations.
+	AuditWriters []io.Writer
+
+	// MaxMessageSize is the size in bytes above which the default logger truncates messages. Zero
+	// means no limit.
+	MaxMessageSize int
+	// Sanitize determines how the default logger handles control characters in messages.
+	Sanitize SanitizeMode
+	// MultilineMarkers marks continuation lines of multi-line messages. See SetMultilineMarkers.
+	MultilineMarkers bool
+
+	// DuplicateKeys determines which field is kept when a record has several with the same key.
+	// See SetDuplicateKeyPolicy.
+	DuplicateKeys DuplicateKeyPolicy
+	// Fingerprints adds a field identifying the logging statement to records. See
+	// SetFingerprints.
+	Fingerprints bool
+	// PprofLabels adds the pprof labels with these keys to records. See SetPprofLabels.
+	PprofLabels []string
+	// Uptime adds the time elapsed since Init to records. See SetUptime.
+	Uptime bool
+	// DeltaMode adds the time elapsed since the previous record to records. See SetDeltaMode.
+	DeltaMode DeltaMode
+	// MetricInterval aggregates metric records in process. See SetMetricInterval.
+	MetricInterval time.Duration
+	// Quotas cap the number of records of each level per time window. See SetQuotas.
+	Quotas []Quota
+
+	// Format is the format of records written to log files and other writers. Records written to
+	// stderr are text, or klog lines for FormatKlog. See SetFormat.
+	Format Format
+	// ServiceName identifies the program in structured records. If empty, the executable name is
+	// used.
+	ServiceName string
+	// Fields are written with every record of the default logger and of the loggers derived from
+	// it with Clone, Named, With and friends, including those derived before Init, for context
+	// shared by the whole program such as its environment, region or deployment. They are sorted
+	// by key and come before all other fields.
+	Fields map[string]interface{}
+	// CEF configures records written in FormatCEF.
+	CEF CEFOptions
+
+	// SplitConsole writes info and audit records to stdout instead of stderr. See SetSplitConsole.
+	SplitConsole bool
+	// HijackStderr captures output written to the stderr of the process by other code as log
+	// records. See HijackStderr.
+	HijackStderr bool
+	// Severity maps levels and verbosities to external severities. See SetSeverityMapping.
+	Severity SeverityFunc
+	// HighlightRules color matching stderr records when Colorful is set. See SetHighlightRules.
+	HighlightRules []HighlightRule
+
+	// Lifecycle makes Init log a "service starting" record with the version of the program and a
+	// hash of the logging configuration, and Close or a fatal record that exits log a "service
+	// exiting" record with the uptime and exit reason, so that uptime and restarts can be derived
+	// from the logs.
+	Lifecycle bool
+
+	// LogCommandLine makes Init log a "command line" record holding the arguments of the program
+	// and the environment variables allowed by LogEnv, so that the flags and settings of a run can
+	// be found in its logs.
+	LogCommandLine bool
+	// LogEnv lists the environment variables logged with the command line: names, or prefixes
+	// followed by "*" such as "MYAPP_*". Variables holding secrets should be left out, or have
+	// their keys, such as "env.MYAPP_TOKEN", listed in RedactKeys.
+	LogEnv []string
+
+	// ResetCounters makes Init restart the numbering of records of each level when it is called
+	// again, instead of continuing it.
+	ResetCounters bool
+
+	// Banner makes Init write a record describing the effective logging configuration, so that it
+	// is known what logging was active when reading the logs later on.
+	Banner bool
+
+	// NoFallback stops Init from falling back to another log directory when LogDir is unusable.
+	NoFallback bool
+	// Container determines whether Init configures the default logger for a container platform
+	// such as Kubernetes or Cloud Run: without a log file, writing records to stdout only, in
+	// Format if it is a structured format and in FormatOTel otherwise. By default, container mode
+	// is detected from the environment.
+	Container ContainerMode
+	// StderrOnly makes Init log to stderr only, without creating a log file. LogDir and the other
+	// log file options are ignored.
+	StderrOnly bool
 }
 
-// Init initializes the logging package.
-func Init(opts *LogOptions) {
-	var logWriters = []io.Writer{}
-	_, exName := path.Split(os.Args[0])
-	pid := os.Getpid()
+// FatalBehavior determines what a logger does after it writes a fatal log message.
+type FatalBehavior int
 
-	if opts.LogDir != "" {
-		logBase = opts.LogDir
-	}
-
-	defaultLogName := fmt.Sprintf("%s/%d-%s-%d.log", logBase, time.Now().Unix(), exName, pid)
-	defaultLogFile, err := os.Create(defaultLogName)
-	if err == nil {
-		logFiles = append(logFiles, defaultLogFile)
-	}
-	defaultLogger = NewLogger(true, opts.Colorful, opts.Timestamp, logWriters...).(*logger)
-	defaultLogger.SetVerbosity(opts.Verbosity)
-	// The default logger skips an extra stack frame when it logs to account for the package
-	// convenience functions.
-	defaultLogger.callerSkip++
+const (
+	// FatalPanic panics with the fatal log message. This is the default behavior.
+	FatalPanic FatalBehavior = iota
+	// FatalExit terminates the process by calling os.Exit with the logger's fatal exit code.
+	FatalExit
+	// FatalCustom calls the logger's fatal handler with the fatal log message. If the handler
+	// returns, so does the logging call. A nil handler falls back to FatalPanic.
+	FatalCustom
+)
 
-	if err != nil {
-		Warningf("unable to open default log file: %v", err)
+// String returns the name of the behavior, such as "panic".
+func (b FatalBehavior) String() string {
+	switch b {
+	case FatalPanic:
+		return "panic"
+	case FatalExit:
+		return "exit"
+	case FatalCustom:
+		return "custom"
 	}
+	return fmt.Sprintf("FatalBehavior(%d)", int(b))
 }
 
 // Logger provides an interface to enhanced logging functionality.
@@ -88,11 +300,14 @@ type Logger interface {
 	Errorf(format string, a ...interface{})
 
 	// Fatal formats a fatal error message using the default formats for its operands, writes to the
-	// error log destinations, and then panics.
+	// error log destinations, and then carries out the logger's FatalBehavior (panics by default).
 	Fatal(a ...interface{})
 	// Fatalf formats a fatal error message according to a format specifier, writes to the error log
-	// destinations, and then panics.
+	// destinations, and then carries out the logger's FatalBehavior (panics by default).
 	Fatalf(format string, a ...interface{})
+	// FatalCode is like Fatal, but exits with the given code instead of the logger's fatal exit
+	// code when the logger is configured with FatalExit.
+	FatalCode(code int, a ...interface{})
 
 	// Info formats an info message using the default formats for its operands and writes to the
 	// info log destinations.
@@ -101,12 +316,33 @@ type Logger interface {
 	// destinations.
 	Infof(format string, a ...interface{})
 
+	// Print is like Info. It is provided for compatibility with the standard library logger.
+	Print(a ...interface{})
+	// Printf is like Infof. It is provided for compatibility with the standard library logger.
+	Printf(format string, a ...interface{})
+	// Println is like Info, but formats its operands like fmt.Println. It is provided for
+	// compatibility with the standard library logger.
+	Println(a ...interface{})
+
 	// Warning formats a warning message using the default formats for its operands and writes to
 	// the warning log destinations.
 	Warning(a ...interface{})
 	// Warningf formats according to a format specifier and writes to the warning log destinations.
 	Warningf(format string, a ...interface{})
 
+	// Infow writes msg followed by fields to the info log destinations. keysAndValues alternates
+	// string keys and their values, as in zap's SugaredLogger, and may also contain Fields:
+	//
+	//	l.Infow("request served", "path", r.URL.Path, "status", 200)
+	Infow(msg string, keysAndValues ...interface{})
+	// Warningw is like Infow, but writes to the warning log destinations.
+	Warningw(msg string, keysAndValues ...interface{})
+	// Errorw is like Infow, but writes to the error log destinations.
+	Errorw(msg string, keysAndValues ...interface{})
+	// Fatalw is like Infow, but writes to the error log destinations and then carries out the
+	// logger's FatalBehavior (panics by default).
+	Fatalw(msg string, keysAndValues ...interface{})
+
 	// VError formats an error message using the default formats for its operands and writes to the
 	// error log destinations if the logger verbosity is sufficiently high.
 	VError(v int, a ...interface{})
@@ -128,14 +364,352 @@ type Logger interface {
 	// if the logger verbosity is sufficiently high.
 	VWarningf(v int, format string, a ...interface{})
 
+	// V returns a Verbose that writes info messages at the given verbosity level and reports
+	// whether that level is enabled.
+	V(level int) Verbose
+
 	// SetVerbosity sets the output verbosity level. Output that is logged at a verbosity level >v
-	// will not be output to the logs.
+	// will not be output to the logs. Changes are recorded in the logs with the old and new levels
+	// and the function that made the change, regardless of verbosity.
 	SetVerbosity(v int)
 
 	// SetDefaultVerbosity sets the default level of verbosity for outgoing logging messages from
 	// this point forward. Note that this affects all future function calls until the next call of
 	// SetDefaultVerbosity.
 	SetDefaultVerbosity(v int)
+
+	// SetFatalBehavior sets what the logger does after writing a fatal message.
+	SetFatalBehavior(b FatalBehavior)
+
+	// SetFatalHandler sets the function called after writing a fatal message and switches the
+	// logger to FatalCustom behavior.
+	SetFatalHandler(h func(msg string))
+
+	// SetFatalTimeout sets how long the logger waits for a fatal message to be written before
+	// panicking. A duration <= 0 disables the timeout.
+	SetFatalTimeout(d time.Duration)
+
+	// SetFatalExitCode sets the exit code used when the logger is configured with FatalExit.
+	SetFatalExitCode(code int)
+
+	// AddFatalHook registers a function that runs after a fatal message is written, but before the
+	// logger carries out its fatal behavior. Hooks run in the order they were added.
+	AddFatalHook(hook func())
+
+	// SetFatalHookTimeout sets how long the logger waits for its fatal hooks to finish. Hooks that
+	// are still running when the timeout expires are abandoned. A duration <= 0 waits forever.
+	SetFatalHookTimeout(d time.Duration)
+
+	// SetFatalStacks sets whether fatal messages include the stacks of all goroutines.
+	SetFatalStacks(enabled bool)
+
+	// SetCrashReports sets the directory where a crash report file is written for each fatal
+	// record, so that post-mortem data survives the rotation or shipping of the logs. Reports hold
+	// the fatal record, the stacks of all goroutines, the command line, the environment variables
+	// listed in CrashReportEnv and the last recent records of the logger and the loggers cloned
+	// from it after the call. An empty dir disables crash reports.
+	SetCrashReports(dir string, recent int)
+
+	// Go runs f in a new goroutine, logging any panic in f as described by Recover.
+	Go(f func())
+
+	// Recover logs a panic in the calling goroutine, along with its stack, and stops the panic from
+	// propagating. It must be called directly by a deferred function, usually as
+	// "defer l.Recover()". Recovered panics are logged as errors, or as fatal messages if the
+	// logger is configured to do so with SetRecoverFatal.
+	Recover()
+
+	// SetRecoverFatal sets whether panics recovered by Go and Recover are logged as fatal messages
+	// instead of errors.
+	SetRecoverFatal(fatal bool)
+
+	// CheckErr logs msg and err as an error message if err is not nil. It reports whether err was
+	// non-nil.
+	CheckErr(err error, msg string) bool
+
+	// Assert logs a message formatted according to a format specifier if cond is false. The message
+	// is logged as a fatal message, or as an error message if the logger is in production mode.
+	Assert(cond bool, format string, a ...interface{})
+
+	// DPanic logs an error message for a condition that should never happen, then panics with the
+	// message if the logger is in development mode, so that such conditions fail loudly in
+	// development and tests without crashing programs that never opted in. Arguments are handled in
+	// the manner of fmt.Print.
+	DPanic(a ...interface{})
+
+	// DPanicf is like DPanic, with the message formatted according to a format specifier.
+	DPanicf(format string, a ...interface{})
+
+	// DPanicw is like DPanic, with fields given as alternating keys and values as with Infow.
+	DPanicw(msg string, keysAndValues ...interface{})
+
+	// If returns a Conditional that logs through the logger only if cond is true, for example
+	// l.If(retries > 3).Warningf("retrying %s", name).
+	If(cond bool) Conditional
+
+	// SetProduction sets whether the logger is in production mode. See Assert.
+	SetProduction(production bool)
+
+	// SetDevelopment sets whether the logger is in development mode. See DPanic.
+	SetDevelopment(development bool)
+
+	// SetRedactKeys sets the sensitive key names whose values are replaced with RedactedValue in
+	// written messages. Keys are matched case-insensitively in "key=value" and "key: value" pairs,
+	// quoted or not. Calling SetRedactKeys with no keys disables redaction.
+	SetRedactKeys(keys ...string)
+
+	// SetScrubRules sets the rules applied, in order, to every written message after key
+	// redaction. Calling SetScrubRules with no rules disables scrubbing.
+	SetScrubRules(rules ...ScrubRule)
+
+	// AddScrubber adds a Scrubber that runs on every written message after the scrub rules.
+	// Scrubbers run in the order they were added.
+	AddScrubber(s Scrubber)
+
+	// AddFieldProvider adds a FieldProvider whose fields are added to every record when it is
+	// written, after goroutine fields and pprof labels and before bound fields. Providers run in
+	// the order they were added.
+	AddFieldProvider(provide FieldProvider)
+
+	// Audit writes a security-relevant event and any additional fields to the audit log
+	// destinations. Audit records are never suppressed by verbosity. Audit returns an error, and
+	// writes nothing, if the event is incomplete or if fields use one of the keys reserved for the
+	// event: "actor", "action", "target" and "outcome". Fields bound to the logger with those keys
+	// are left out of audit records.
+	Audit(event AuditEvent, fields ...Field) error
+
+	// Event logs an info record of the event type name, defined with DefineEvent, with the event
+	// name as its message and as the leading "event" field. Event returns an error, and logs
+	// nothing, if the type isn't defined or fields don't match its definition.
+	Event(name string, fields ...Field) error
+
+	// Count logs a counter metric record, an info record with the metric name as its message and
+	// "metric", "type" and "value" fields followed by fields, for programs whose only telemetry
+	// channel is their logs. When metrics are aggregated, the deltas of records with the same
+	// name and fields are summed instead. See SetMetricInterval.
+	Count(name string, delta int64, fields ...Field)
+
+	// Gauge logs a gauge metric record like Count. When metrics are aggregated, the last value of
+	// records with the same name and fields is logged.
+	Gauge(name string, value float64, fields ...Field)
+
+	// SetMetricInterval aggregates the metric records of Count and Gauge in process, logging one
+	// record per metric name and fields every interval, attributed to the first call. A zero
+	// interval logs every record as it comes, the default. Aggregated records are also logged
+	// by Flush, and when the interval is changed.
+	SetMetricInterval(interval time.Duration)
+
+	// SetAuditOutput sets the destinations for audit records, which also go to stderr if the logger
+	// logs to stderr. With no writers, audit records go to the logger's regular destinations.
+	SetAuditOutput(writers ...io.Writer)
+
+	// SetMaxMessageSize sets the size in bytes above which messages are truncated. Truncated
+	// messages end with a "...[truncated N bytes]" marker. A size <= 0 means no limit.
+	SetMaxMessageSize(n int)
+
+	// SetSanitizeMode sets how control characters and embedded newlines in messages are handled.
+	SetSanitizeMode(mode SanitizeMode)
+
+	// SetMultilineMarkers sets whether continuation lines of multi-line records, such as stack
+	// traces, are indented and marked with the tag of their record, as in "  |I0042| ". Text log
+	// consumers can then reassemble records unambiguously.
+	SetMultilineMarkers(enabled bool)
+
+	// SetDuplicateKeyPolicy sets what happens when a record has several fields with the same key,
+	// for example because a middleware bound a key with With that is passed again at the call site.
+	// Fields are considered from the most general to the most specific: the fields of
+	// LogOptions.Fields, the component, the uptime and delta, goroutine fields, pprof labels,
+	// provided fields, bound fields and finally the fields passed at the call site. The default is
+	// DuplicateKeysLastWins.
+	SetDuplicateKeyPolicy(policy DuplicateKeyPolicy)
+
+	// SetFingerprints sets whether records carry a "fingerprint" field: a hash of their call site
+	// and message template, such as the format string of Infof, that is the same for all records
+	// of a logging statement whatever values are interpolated into their messages. Records of
+	// Info and similar methods without a template are fingerprinted by their call site alone.
+	// Fingerprints change when the statement moves to another line or file.
+	SetFingerprints(enabled bool)
+
+	// SetPprofLabels sets the keys of the pprof labels, set with pprof.Do or
+	// pprof.SetGoroutineLabels, that records carry as fields when the logging goroutine has them,
+	// so that CPU profiles and logs of the same request can be connected. No labels are added if
+	// no keys are given, which is the default. Labels are read from the runtime, whose layout of
+	// them is checked for each Go release: built with a release newer than those known to this
+	// package, records carry no labels.
+	SetPprofLabels(keys ...string)
+
+	// SetUptime sets whether records carry an "uptime" field: the time elapsed since Init was last
+	// called, or since the program started if it hasn't been. Unlike timestamps, it is measured
+	// with the monotonic clock, so it doesn't jump when the wall clock is adjusted.
+	SetUptime(enabled bool)
+
+	// SetDeltaMode sets whether records carry a "delta" field holding the time elapsed since the
+	// previous record of the logger or of the logging goroutine, measured with the monotonic
+	// clock. The first record has none.
+	SetDeltaMode(mode DeltaMode)
+
+	// SetQuotas caps the number of records of levels per time window, replacing any previous
+	// quotas, so that a runaway loop can't fill the disk or run up ingestion costs. Records over
+	// a quota are dropped, and a warning record notes when suppression starts and how many
+	// records were dropped once the window is over. Quotas on fatal and audit records are
+	// ignored, since those must never be lost.
+	SetQuotas(quotas ...Quota)
+
+	// SetHighlightRules sets rules that color matching records written to stderr differently from
+	// other records of their level, when the logger is colorful, so that they stand out:
+	//
+	//	l.SetHighlightRules(log.HighlightRule{Substring: "deadline exceeded", Color: log.ColorMagenta})
+	//
+	// The first matching rule wins.
+	SetHighlightRules(rules ...HighlightRule)
+
+	// SetSeverityMapping sets the function mapping levels and verbosities to the severities used
+	// by structured formats and network sinks. The default is DefaultSeverity; a nil f restores
+	// it.
+	SetSeverityMapping(f SeverityFunc)
+
+	// SetFormat sets the format of records written to the logger's writers. Records written to
+	// stderr stay text, so that they are readable on a terminal, except with FormatKlog, which is
+	// a text format itself. The default is FormatText.
+	SetFormat(format Format)
+
+	// SetServiceName sets the name identifying the program in structured records, such as the
+	// "service.name" resource attribute of FormatOTel. The default is the executable name.
+	SetServiceName(name string)
+
+	// SetTimestampGranularity makes the logger format the timestamps of text records at most once
+	// per granularity, such as time.Millisecond, reusing the formatted text for the other records
+	// in the same interval. Timestamps are then truncated to the granularity. Zero, the default,
+	// formats the exact time of every record.
+	SetTimestampGranularity(granularity time.Duration)
+
+	// SetCEFOptions configures the header and extensions of records written in FormatCEF.
+	SetCEFOptions(opts CEFOptions)
+
+	// AddWriter adds a destination for log records. It may be called while the logger is in use,
+	// for example to capture a debug file temporarily during an incident.
+	AddWriter(w io.Writer)
+
+	// RemoveWriter removes a destination added with AddWriter or passed to NewLogger, reporting
+	// whether it was found. The writer isn't closed.
+	RemoveWriter(w io.Writer) bool
+
+	// SetOutput replaces all of the logger's destinations with w, keeping its counters and other
+	// settings. A nil w leaves the logger without destinations other than stderr.
+	SetOutput(w io.Writer)
+
+	// ReplaceWriter replaces the destination old with new, reporting whether old was found.
+	ReplaceWriter(old, new io.Writer) bool
+
+	// SetColorful sets whether records written to stderr are colored by level, for example to turn
+	// colors off once a --no-color flag has been parsed or output turns out to be redirected.
+	SetColorful(colorful bool)
+
+	// SetTimestamp sets whether text records start with a timestamp.
+	SetTimestamp(timestamp bool)
+
+	// SetSplitConsole sets whether info and audit records are written to stdout instead of stderr
+	// when the logger logs to stderr, leaving warnings, errors and fatal records on stderr. This is
+	// the convention many scripts and CI systems consuming command line tools rely on.
+	SetSplitConsole(split bool)
+
+	// SetStderrEnabled temporarily silences stderr output of a logger that logs to stderr, or
+	// enables it again, for example while an interactive tool renders a full-screen interface.
+	// Fatal records are written regardless, so that a crash is never silent.
+	SetStderrEnabled(enabled bool)
+
+	// SetFileEnabled temporarily silences the logger's writers that are files, such as the log file
+	// opened by Init, or enables them again. Other destinations, such as network sinks, notifiers and
+	// AsyncWriter, keep receiving records, and audit and fatal records are written to every
+	// destination regardless.
+	SetFileEnabled(enabled bool)
+
+	// Flush waits until records buffered by destinations with a Flush method, such as AsyncWriter,
+	// have been written, after logging any aggregated metric records. Fatal records are always
+	// flushed before the logger's FatalBehavior is carried out, waiting up to
+	// DefaultFatalFlushTimeout.
+	Flush() error
+
+	// TimeTrack logs how long an operation took since start, as a "duration" field. It is meant to
+	// be deferred at the beginning of the operation:
+	//
+	//	defer l.TimeTrack(time.Now(), "rebuild index")
+	TimeTrack(start time.Time, operation string)
+
+	// Timed calls f and logs how long it took as a "duration" field, returning f's error. If f
+	// fails, the message is logged as an error and includes the error as a field.
+	Timed(operation string, f func() error) error
+
+	// SetTimingLevel sets the level at which TimeTrack and Timed log successful operations. The
+	// default is InfoLevel. Levels above ErrorLevel are treated as ErrorLevel.
+	SetTimingLevel(level Level)
+
+	// Command returns a Cmd running the named program with the given arguments, like
+	// exec.CommandContext, with its output logged line by line: stdout as info records and stderr
+	// as warnings, with the base name of the program as a "command" field. The records are
+	// attributed to the call to Command.
+	Command(ctx context.Context, name string, arg ...string) *Cmd
+
+	// Begin logs the start of a long-running operation and returns a Scope whose End method logs
+	// its completion, status and duration:
+	//
+	//	scope := l.Begin("rebuild-index", log.F("shard", 3))
+	//	err := rebuild()
+	//	scope.End(err)
+	Begin(name string, fields ...Field) *Scope
+
+	// Clone returns an independent copy of the logger with its own counters, writing to the same
+	// destinations unless opts override them. Changing the settings of the copy doesn't affect the
+	// original, which makes it possible to give a subsystem its own verbosity:
+	//
+	//	dbLog := log.Clone(log.WithVerbosity(2))
+	Clone(opts ...Option) Logger
+
+	// Named returns a child of the logger for the component name. Its records carry the component
+	// path, made of the names of all its ancestors joined with dots, in their prefix and as a
+	// "component" field:
+	//
+	//	httpLog := log.Named("server").Named("http") // [I0000 server.http] ... component=server.http
+	//
+	// The child inherits the verbosity of the logger, including later changes, until its own
+	// verbosity is set. It writes to the logger's writers as well as any added to it, until its
+	// writers are replaced with SetOutput. Other settings are copied as with Clone.
+	Named(name string) Logger
+
+	// With returns a clone of the logger that writes fields after the message of every record.
+	With(fields ...Field) Logger
+
+	// WithGroup returns a clone of the logger that puts the keys of fields bound with With and
+	// passed to Infow and friends in the namespace name, so that they render as "name.key". Groups
+	// nest, and keep fields attached by different middlewares from colliding:
+	//
+	//	dbLog := l.WithGroup("db")
+	//	dbLog.Infow("query done", "query", q, "duration", d) // ... db.query=... db.duration=...
+	WithGroup(name string) Logger
+
+	// WithTime returns a clone of the logger whose records carry t as their time instead of the
+	// time they are written, for components that ingest and re-log historical or replayed events:
+	//
+	//	l.WithTime(event.Time).Infow("replayed", "id", event.ID)
+	//
+	// A zero t restores the write time.
+	WithTime(t time.Time) Logger
+
+	// WriteRecord writes a record produced elsewhere, such as by a bridge from another logging
+	// library or a relay from another process, to the logger's destinations as is. The logger's
+	// verbosity, quotas, bound fields and formatting apply as they would to its own records, but
+	// a fatal record is only written: it doesn't trigger the fatal behavior.
+	WriteRecord(r Record)
+
+	// Subscribe returns a channel receiving the records written by the logger from now on that
+	// filter selects, or all of them if filter is nil, so that other parts of the process can
+	// observe the log stream live. The logger shares its subscriptions with the loggers derived
+	// from it by Clone, Named, With and friends, and with the one it was derived from.
+	//
+	// Records are delivered with their messages and fields redacted and scrubbed. A consumer
+	// falling more than SubscriptionBuffer records behind misses records. Calling the returned
+	// function ends the subscription and closes the channel.
+	Subscribe(filter RecordFilter) (<-chan Record, func())
 }
 
 // logger implements the Logger interface.
@@ -143,19 +717,58 @@ type logger struct {
 	// Stores counts of log levels, mapping log levels to recorded counts. Using a map instead of
 	// a slice gives us zero values for an unbounded set of log levels without having to iterate
 	// make any special future alterations to the way log levels are counted.
-	count map[int]int64
+	count map[Level]int64
 
 	// The mutex used to synchronize operations on the log object.
 	mu sync.Mutex
 
+	// the last timestamp formatted with a timestampGranularity, and the granularity interval it
+	// was formatted for.
+	timestampText     string
+	timestampInterval int64
+
+	// the time the last record was written, for DeltaLogger.
+	lastRecord time.Time
+
+	// the current window of each level with a quota.
+	quotaWindows map[Level]*quotaWindow
+
+	loggerConfig
+}
+
+// loggerConfig holds the configuration of a logger. It is kept apart from the counters and the
+// mutex so that Clone can copy it in one go.
+type loggerConfig struct {
 	// callerSkip is used to determine how many stack frames to skip for logging. Usually this will
 	// be 3, but the default logger will skip an extra frame to bypass the package-level convenience
 	// functions.
 	callerSkip int
 
-	// verbosity required to output logging messages
+	// dot-separated component path of a logger returned by Named, or empty for a root logger.
+	name string
+
+	// determines whether records carry the fields of LogOptions.Fields, which is the case for the
+	// default logger and the loggers derived from it.
+	global bool
+
+	// fields bound with With, written after the message of every record.
+	fields []Field
+
+	// prefix, such as "db.", given to the keys of fields bound with With and passed to Infow and
+	// friends.
+	group string
+
+	// logger from which a logger returned by Named inherits its verbosity and writers, or nil for a
+	// root logger.
+	parent *logger
+
+	// verbosity required to output logging messages. Ignored in favour of the parent's verbosity
+	// unless ownVerbosity is set.
 	verbosity int
 
+	// determines whether verbosity overrides the verbosity inherited from parent.
+	ownVerbosity bool
+
 	// default verbosity level for logging calls
 	defaultVerbosity int
 
@@ -170,132 +783,682 @@ type logger struct {
 	// determines whether or not the logger will write out a timestamp.
 	timestamp bool
 
-	// writer to which file logs will be written.
-	writer io.Writer
+	// determines whether info and audit records go to stdout instead of stderr.
+	splitConsole bool
+
+	// determine whether stderr and the writers are temporarily silenced.
+	stderrDisabled bool
+	filesDisabled  bool
+
+	// maps levels and verbosities to the severities of external systems.
+	severity SeverityFunc
+
+	// rules coloring matching stderr records when colorful is set.
+	highlightRules []HighlightRule
+
+	// interval within which text timestamps are formatted once and reused. Zero formats every one.
+	timestampGranularity time.Duration
+
+	// time given to records instead of the time they are written, unless zero.
+	eventTime time.Time
+
+	// writers to which file logs will be written. Those of a logger with a parent are written to in
+	// addition to the parent's writers, unless ownWriters is set.
+	writers []io.Writer
+
+	// determines whether writers replace the writers inherited from parent.
+	ownWriters bool
+
+	// writers to which audit records will be written instead of writers, if not empty.
+	auditWriters []io.Writer
+
+	// size in bytes above which messages are truncated. Zero means no limit.
+	maxMessageSize int
+
+	// determines how control characters in messages are handled.
+	sanitizeMode SanitizeMode
+
+	// determines whether continuation lines of multi-line records are marked.
+	multilineMarkers bool
+
+	// determines which field is kept when a record has several with the same key.
+	duplicateKeys DuplicateKeyPolicy
+
+	// aggregates metric records in process, if not nil.
+	metrics *metricAggregator
+
+	// determines whether metrics was set up by this logger rather than inherited by a clone.
+	ownMetrics bool
+
+	// live consumers of the records, shared with the loggers derived from this one.
+	subscriptions *subscriptions
+
+	// determines whether records carry a fingerprint field identifying their logging statement.
+	fingerprints bool
+
+	// keys of the pprof labels of the logging goroutine added to records.
+	pprofLabels []string
+
+	// determines whether records carry the time elapsed since Init.
+	uptime bool
+
+	// determines whether records carry the time elapsed since the previous record.
+	deltaMode DeltaMode
+
+	// functions returning fields added to records when they are written.
+	fieldProviders []FieldProvider
+
+	// caps on the number of records of each level per time window.
+	quotas []Quota
+
+	// format of records written to writers.
+	format Format
+
+	// name of the program in structured records.
+	serviceName string
+
+	// configuration of records written in FormatCEF.
+	cefOptions CEFOptions
+
+	// level at which TimeTrack and Timed log successful operations.
+	timingLevel Level
+
+	// determines what happens after a fatal message is written.
+	fatalBehavior FatalBehavior
+
+	// called after a fatal message is written when fatalBehavior is FatalCustom.
+	fatalHandler func(msg string)
+
+	// how long to wait for a fatal message to be written. Zero disables the timeout.
+	fatalTimeout time.Duration
+
+	// exit code used when fatalBehavior is FatalExit.
+	fatalExitCode int
+
+	// functions run after a fatal message is written and before the fatal behavior.
+	fatalHooks []func()
+
+	// how long to wait for fatalHooks to finish. Zero waits forever.
+	fatalHookTimeout time.Duration
+
+	// determines whether fatal messages include the stacks of all goroutines.
+	fatalStacks bool
+
+	// directory of crash reports written for fatal records, and the recent records they include.
+	crashReportDir string
+	recent         *recordRing
+
+	// determines whether recovered panics are logged as fatal messages instead of errors.
+	recoverFatal bool
+
+	// determines whether the logger is in production mode, which softens failed assertions.
+	production bool
+
+	// determines whether the logger is in development mode, where DPanic panics.
+	development bool
+
+	// matches key/value pairs whose values must be redacted, or nil if nothing is redacted.
+	redactor *regexp.Regexp
+
+	// rules applied to every message after redaction.
+	scrubRules []ScrubRule
+
+	// scrubbers run on every message after scrubRules.
+	scrubbers []Scrubber
 }
 
-// NewLogger returns a new Logger that logs to the specified files..
+// NewLogger returns a new Logger that logs to the specified files. Each record, including its
+// trailing newline, is written to each destination with a single call to Write.
 func NewLogger(logToStderr bool, colorful bool, timestamp bool, logFiles ...io.Writer) Logger {
 	l := &logger{
-		count:       map[int]int64{},
-		callerSkip:  3,
-		logToStderr: logToStderr,
-		colorful:    colorful,
-		writer:      io.MultiWriter(logFiles...),
-		timestamp:   timestamp,
+		count: map[Level]int64{},
+		loggerConfig: loggerConfig{
+			callerSkip:  3,
+			logToStderr: logToStderr,
+			colorful:    colorful,
+			writers:     append([]io.Writer(nil), logFiles...),
+			timestamp:   timestamp,
+
+			fatalTimeout:  DefaultFatalTimeout,
+			fatalExitCode: DefaultFatalExitCode,
+
+			fatalHookTimeout: DefaultFatalHookTimeout,
+
+			serviceName: executableName(),
+
+			subscriptions: &subscriptions{},
+		},
 	}
 	return l
 }
 
-// write takes the log level and a logging string produced by log or logf and writes the log
-// message, updating the count for that log level.
-func (l *logger) write(logLevel int, s, file string, line int, callerOK bool) {
-	var color string
-	file = filepath.Base(file)
+// write takes the log level and verbosity, a logging string produced by log, logf or logw and the
+// fields passed at the call site, and writes the log message, updating the count for that log
+// level. It returns the complete text log line, whatever the logger's format, or an empty string
+// if the record was over its level's quota.
+func (l *logger) write(logLevel Level, verbosity int, msg string, fields []Field, file string, line int, callerOK bool) string {
+	if !l.admit(logLevel, file, line, callerOK) {
+		return ""
+	}
+	return l.emit(logLevel, verbosity, msg, fields, file, line, callerOK)
+}
+
+// emit is like write, but ignores quotas.
+func (l *logger) emit(logLevel Level, verbosity int, msg string, fields []Field, file string, line int, callerOK bool) string {
 	if !callerOK {
 		file, line = "unknown file", 0
 	}
-
-	var prefix string
-	if logLevel == logFatal {
-		prefix = fmt.Sprintf("[%s]", logPrefix[logFatal])
+	t := l.eventTime
+	if t.IsZero() {
+		t = time.Now()
+	}
+	r := &Record{
+		Time:      t,
+		Level:     logLevel,
+		Verbosity: max(verbosity, 0),
+		Seq:       l.count[logLevel],
+		File:      file,
+		Line:      line,
+		Message:   msg,
+	}
+	if logLevel == AuditLevel {
+		r.Fields = l.auditRecordFields(fields)
 	} else {
-		prefix = fmt.Sprintf("[%s%04d]", logPrefix[logLevel], l.count[logLevel])
+		r.Fields = l.recordFields(fields)
+	}
+	if logLevel == FatalLevel && l.fatalStacks {
+		r.Stacks = allStacks()
 	}
+	return l.output(r)
+}
 
-	if l.timestamp {
-		prefix = fmt.Sprintf("%s %s", time.Now().String(), prefix)
+// output writes r to the logger's destinations, returning its text form. It must be called with
+// l.mu held.
+func (l *logger) output(r *Record) string {
+	var color string
+	logLevel := r.Level
+	writers := l.inheritedWriters()
+	if logLevel == AuditLevel && len(l.auditWriters) > 0 {
+		writers = l.auditWriters
+	}
+	// A crash must never be silent and compliance records must never be lost, so fatal and audit
+	// records ignore the quiet mode toggles.
+	if l.filesDisabled && logLevel != FatalLevel && logLevel != AuditLevel {
+		writers = withoutFiles(writers)
+	}
+
+	start := time.Now()
+	s := l.formatText(r)
+	if l.recent != nil {
+		l.recent.add(s)
+	}
+	// Don't bother encoding records that only go to stderr.
+	var out []byte
+	switch {
+	case len(writers) == 0:
+	case l.format == FormatText, l.format == FormatKlog:
+		out = textLine(s)
+	default:
+		out = l.encode(r)
 	}
-	s = fmt.Sprintf("%s %s:%d: %s", prefix, file, line, s)
+	reportRecord(logLevel, time.Since(start))
 
-	if l.logToStderr {
+	if l.logToStderr && (!l.stderrDisabled || logLevel == FatalLevel) {
 		if l.colorful {
-			color = logColor[logLevel]
+			color = highlight(l.highlightRules, s, logColor[logLevel])
 		}
-		fmt.Fprintln(os.Stderr, color+s+defaultColor)
+		console := consoleStderr.Load()
+		if l.splitConsole && (logLevel == InfoLevel || logLevel == AuditLevel) {
+			console = os.Stdout
+		}
+		fmt.Fprintln(console, color+s+defaultColor)
 	}
 
-	if logLevel == logFatal {
-		go func() {
-			time.Sleep(time.Second / 2)
-			panic(fmt.Errorf("timeout waiting for fatal log to write to disk. Log message follows:\n%s", s))
-		}()
-		fmt.Fprintln(l.writer, s)
-		// Fatal logs are a little different from everything else because we panic at the end.
-		panic(s)
+	if logLevel == FatalLevel {
+		if l.fatalTimeout > 0 {
+			// The timer is stopped once the write completes so that a recovered fatal panic doesn't
+			// bring the program down later on.
+			timer := time.AfterFunc(l.fatalTimeout, func() {
+				panic(fmt.Errorf("timeout waiting for fatal log to write to disk. Log message follows:\n%s", s))
+			})
+			defer timer.Stop()
+		}
+		writeRecord(writers, logLevel, out)
+		if l.crashReportDir != "" {
+			l.writeCrashReport(r, s)
+		}
+		// Make sure the record made it past any queue before the program goes down, but don't let a
+		// hung collector hold up the crash.
+		audit := l.auditWriters
+		flushWithin(func() error {
+			return errors.Join(flushWriters(writers), flushWriters(audit))
+		}, DefaultFatalFlushTimeout)
+		l.publish(r)
+		return s
 	}
 
-	fmt.Fprintln(l.writer, s)
-
+	writeRecord(writers, logLevel, out)
 	l.count[logLevel]++
+	l.publish(r)
+	return s
+}
+
+// withoutFiles returns the writers that aren't files, which SetFileEnabled silences.
+func withoutFiles(writers []io.Writer) []io.Writer {
+	var others []io.Writer
+	for _, w := range writers {
+		if _, ok := w.(*os.File); !ok {
+			others = append(others, w)
+		}
+	}
+	return others
+}
+
+// errFlushTimeout is returned by flushWithin when flushing takes too long.
+var errFlushTimeout = errors.New("timeout flushing log destinations")
+
+// flushWithin calls flush, giving up on it after d. Flushing carries on in the background if it
+// times out.
+func flushWithin(flush func() error, d time.Duration) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- flush()
+	}()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return errFlushTimeout
+	}
+}
+
+// flusher is implemented by writers that buffer records, such as AsyncWriter.
+type flusher interface {
+	Flush() error
+}
+
+// flushWriters flushes those of writers that buffer records, returning their errors.
+func flushWriters(writers []io.Writer) error {
+	var errs []error
+	for _, w := range writers {
+		if f, ok := w.(flusher); ok {
+			if err := f.Flush(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
 }
 
-// log is used to print a log message using the default format interfaces (Info, Error, Warning)
-func (l *logger) log(verbosity int, logLevel int, a ...interface{}) {
-	_, file, line, ok := runtime.Caller(l.callerSkip - 1)
+// SetColorful implements the Logger interface.
+func (l *logger) SetColorful(colorful bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.colorful = colorful
+}
+
+// SetTimestamp implements the Logger interface.
+func (l *logger) SetTimestamp(timestamp bool) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	if verbosity > l.verbosity {
-		return
-	}
 
-	s := fmt.Sprint(a...)
-	l.write(logLevel, s, file, line, ok)
+	l.timestamp = timestamp
 }
 
-// logf is used to print a log message using the format string interfaces (Infof, Errof, Warningf)
-func (l *logger) logf(verbosity int, logLevel int, format string, a ...interface{}) {
-	_, file, line, ok := runtime.Caller(l.callerSkip - 1)
+// SetSplitConsole implements the Logger interface.
+func (l *logger) SetSplitConsole(split bool) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	if verbosity > l.verbosity {
+
+	l.splitConsole = split
+}
+
+// SetStderrEnabled implements the Logger interface.
+func (l *logger) SetStderrEnabled(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stderrDisabled = !enabled
+}
+
+// SetFileEnabled implements the Logger interface.
+func (l *logger) SetFileEnabled(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.filesDisabled = !enabled
+}
+
+// Flush implements the Logger interface.
+func (l *logger) Flush() error {
+	l.flushMetrics()
+
+	l.mu.Lock()
+	writers := append([]io.Writer(nil), l.inheritedWriters()...)
+	writers = append(writers, l.auditWriters...)
+	l.mu.Unlock()
+
+	return flushWriters(writers)
+}
+
+// formatText returns r as a text log line, in klog's format if the logger is configured with
+// FormatKlog. It must be called with l.mu held.
+func (l *logger) formatText(r *Record) string {
+	s := r.Message
+	if len(r.Fields) > 0 {
+		s += " " + formatFields(r.Fields)
+	}
+	s = scrub(l.scrubRules, l.scrubbers, redact(l.redactor, s))
+	s = truncate(sanitize(s, l.sanitizeMode), l.maxMessageSize)
+	if l.format == FormatKlog {
+		return l.formatKlog(r, s)
+	}
+
+	// tag identifies the record by level and sequence number.
+	var tag string
+	if r.Level == FatalLevel {
+		tag = logPrefix[FatalLevel]
+	} else {
+		tag = fmt.Sprintf("%s%04d", logPrefix[r.Level], r.Seq)
+	}
+	prefix := "[" + tag + "]"
+	if l.name != "" {
+		prefix = "[" + tag + " " + l.name + "]"
+	}
+
+	if l.timestamp {
+		prefix = l.formatTimestamp(r.Time) + " " + prefix
+	}
+	s = prefix + " " + filepath.Base(r.File) + ":" + strconv.Itoa(r.Line) + ": " + s
+	if r.Stacks != nil {
+		s = fmt.Sprintf("%s\n%s", s, r.Stacks)
+	}
+	if l.multilineMarkers {
+		s = markContinuationLines(s, tag)
+	}
+	return s
+}
+
+// textLine returns the text log line s with its trailing newline.
+func textLine(s string) []byte {
+	line := make([]byte, 0, len(s)+1)
+	return append(append(line, s...), '\n')
+}
+
+// LevelWriter is implemented by writers that handle records differently depending on their level,
+// such as Notifier. Loggers call WriteLevel instead of Write for such writers.
+type LevelWriter interface {
+	io.Writer
+	WriteLevel(level Level, p []byte) (int, error)
+}
+
+// writeRecord writes the encoded record b of level to each of writers with a single call to Write,
+// or WriteLevel for LevelWriters. A failing writer doesn't keep b from the others.
+func writeRecord(writers []io.Writer, level Level, b []byte) {
+	for _, w := range writers {
+		var err error
+		if lw, ok := w.(LevelWriter); ok {
+			_, err = lw.WriteLevel(level, b)
+		} else {
+			_, err = w.Write(b)
+		}
+		if err != nil {
+			reportWriteError(err)
+		}
+	}
+}
+
+// fatal carries out the logger's fatal behavior for the already written fatal log line s, exiting
+// with the logger's exit code if the logger is configured to exit. fatal must be called without
+// holding l.mu so that custom handlers are free to use the logger.
+func (l *logger) fatal(s string) {
+	l.mu.Lock()
+	code := l.fatalExitCode
+	l.mu.Unlock()
+
+	l.fatalCode(s, code)
+}
+
+// fatalCode is like fatal, but exits with code.
+func (l *logger) fatalCode(s string, code int) {
+	l.mu.Lock()
+	behavior, handler := l.fatalBehavior, l.fatalHandler
+	hooks, hookTimeout := l.fatalHooks, l.fatalHookTimeout
+	l.mu.Unlock()
+
+	runFatalHooks(hooks, hookTimeout)
+
+	switch {
+	case behavior == FatalExit:
+		// Attribute the record to the caller of Fatal and similar methods, which call fatal.
+		l.logExit(l.callerSkip, fmt.Sprintf("fatal, exit code %d", code))
+		flushWithin(l.Flush, DefaultFatalFlushTimeout)
+		os.Exit(code)
+	case behavior == FatalCustom && handler != nil:
+		handler(s)
+	default:
+		panic(s)
+	}
+}
+
+// allStacks returns a dump of the stacks of all goroutines.
+func allStacks() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// logPanic logs the recovered panic value r along with the stack of the panicking goroutine. The
+// message is attributed to the location of the panic rather than the location of the recovery.
+func (l *logger) logPanic(r interface{}) {
+	file, line, ok := panicSite()
+	s := fmt.Sprintf("panic: %v\n%s", r, debug.Stack())
+
+	l.mu.Lock()
+	logLevel := ErrorLevel
+	if l.recoverFatal {
+		logLevel = FatalLevel
+	}
+	s = l.write(logLevel, 0, s, nil, file, line, ok)
+	l.mu.Unlock()
+
+	if logLevel == FatalLevel {
+		l.fatal(s)
+	}
+}
+
+// panicSite returns the location of the panic that the calling goroutine is recovering from.
+func panicSite() (file string, line int, ok bool) {
+	pc := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pc[:runtime.Callers(2, pc)])
+	panicking := false
+	for {
+		frame, more := frames.Next()
+		if frame.Function == "runtime.gopanic" {
+			panicking = true
+		} else if panicking && !strings.HasPrefix(frame.Function, "runtime.") {
+			return frame.File, frame.Line, true
+		}
+		if !more {
+			return "", 0, false
+		}
+	}
+}
+
+// sprintln formats its operands like fmt.Sprintln, without the trailing newline.
+func sprintln(a ...interface{}) string {
+	s := fmt.Sprintln(a...)
+	return s[:len(s)-1]
+}
+
+// runFatalHooks runs hooks in order, giving up on them once timeout has elapsed. A panicking hook
+// doesn't prevent the remaining hooks from running.
+func runFatalHooks(hooks []func(), timeout time.Duration) {
+	if len(hooks) == 0 {
 		return
 	}
 
-	s := fmt.Sprintf(format, a...)
-	l.write(logLevel, s, file, line, ok)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, hook := range hooks {
+			func() {
+				defer func() { recover() }()
+				hook()
+			}()
+		}
+	}()
+
+	if timeout <= 0 {
+		<-done
+		return
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+	}
+}
+
+// log is used to print a log message using the default format interfaces (Info, Error, Warning). It
+// returns the written log line, or an empty string if nothing was written.
+func (l *logger) log(verbosity int, logLevel Level, a ...interface{}) string {
+	return l.logDepth(l.callerSkip, verbosity, logLevel, a...)
+}
+
+// logDepth is like log, but attributes the message to the caller skip stack frames up, where a
+// skip of 0 identifies logDepth itself.
+func (l *logger) logDepth(skip int, verbosity int, logLevel Level, a ...interface{}) string {
+	file, line, ok := caller(skip)
+	l.mu.Lock()
+	// Fatal records are written whatever the verbosity, as the program stops after them.
+	if verbosity > l.inheritedVerbosity() && logLevel != FatalLevel {
+		l.mu.Unlock()
+		return ""
+	}
+
+	s := l.write(logLevel, verbosity, fmt.Sprint(a...), l.fingerprintFields(nil, file, line, ""), file,
+		line, ok)
+	l.mu.Unlock()
+	return s
+}
+
+// logf is used to print a log message using the format string interfaces (Infof, Errof, Warningf).
+// It returns the written log line, or an empty string if nothing was written.
+func (l *logger) logf(verbosity int, logLevel Level, format string, a ...interface{}) string {
+	return l.logfDepth(l.callerSkip, verbosity, logLevel, format, a...)
+}
+
+// logfDepth is like logf, but attributes the message to the caller skip stack frames up, where a
+// skip of 0 identifies logfDepth itself.
+func (l *logger) logfDepth(skip int, verbosity int, logLevel Level, format string, a ...interface{}) string {
+	file, line, ok := caller(skip)
+	l.mu.Lock()
+	if verbosity > l.inheritedVerbosity() && logLevel != FatalLevel {
+		l.mu.Unlock()
+		return ""
+	}
+
+	s := l.write(logLevel, verbosity, fmt.Sprintf(format, a...),
+		l.fingerprintFields(nil, file, line, format), file, line, ok)
+	l.mu.Unlock()
+	return s
+}
+
+// logw is used to print a log message followed by fields (Infow, Errorw, Warningw). It returns
+// the written log line, or an empty string if nothing was written.
+func (l *logger) logw(verbosity int, logLevel Level, msg string, fields []Field) string {
+	return l.logwDepth(l.callerSkip, verbosity, logLevel, msg, fields)
+}
+
+// logwDepth logs msg followed by fields, attributing the message to the caller skip stack frames
+// up, where a skip of 0 identifies logwDepth itself. It returns the written log line, or an empty
+// string if nothing was written.
+func (l *logger) logwDepth(skip int, verbosity int, logLevel Level, msg string, fields []Field) string {
+	file, line, ok := caller(skip)
+	l.mu.Lock()
+	if verbosity > l.inheritedVerbosity() && logLevel != FatalLevel {
+		l.mu.Unlock()
+		return ""
+	}
+
+	s := l.write(logLevel, verbosity, msg, l.fingerprintFields(fields, file, line, msg), file, line,
+		ok)
+	l.mu.Unlock()
+	return s
 }
 
 // Info implements the Logger interface.
 func (l *logger) Info(a ...interface{}) {
-	l.log(l.defaultVerbosity, logInfo, a...)
+	l.log(l.defaultVerbosity, InfoLevel, a...)
+}
+
+// Print implements the Logger interface.
+func (l *logger) Print(a ...interface{}) {
+	l.log(l.defaultVerbosity, InfoLevel, a...)
+}
+
+// Println implements the Logger interface.
+func (l *logger) Println(a ...interface{}) {
+	l.log(l.defaultVerbosity, InfoLevel, sprintln(a...))
 }
 
 // Warning implements the Logger interface.
 func (l *logger) Warning(a ...interface{}) {
-	l.log(l.defaultVerbosity, logWarning, a...)
+	l.log(l.defaultVerbosity, WarningLevel, a...)
 }
 
 // Error implements the Logger interface.
 func (l *logger) Error(a ...interface{}) {
-	l.log(l.defaultVerbosity, logError, a...)
+	l.log(l.defaultVerbosity, ErrorLevel, a...)
 }
 
 // Fatal implements the Logger interface.
 func (l *logger) Fatal(a ...interface{}) {
-	// Verbosity level is 0 because we always log fatal messages.
-	l.log(0, logFatal, a...)
+	// Verbosity level is 0 because we always log fatal messages. Fatal logs are a little different
+	// from everything else because the logger stops the normal flow of the program once the
+	// message has been written.
+	l.fatal(l.log(0, FatalLevel, a...))
+}
+
+// FatalCode implements the Logger interface.
+func (l *logger) FatalCode(code int, a ...interface{}) {
+	l.fatalCode(l.log(0, FatalLevel, a...), code)
 }
 
 // Infof implements the Logger interface.
 func (l *logger) Infof(format string, a ...interface{}) {
-	l.logf(l.defaultVerbosity, logInfo, format, a...)
+	l.logf(l.defaultVerbosity, InfoLevel, format, a...)
+}
+
+// Printf implements the Logger interface.
+func (l *logger) Printf(format string, a ...interface{}) {
+	l.logf(l.defaultVerbosity, InfoLevel, format, a...)
 }
 
 // Warningf implements the Logger interface.
 func (l *logger) Warningf(format string, a ...interface{}) {
-	l.logf(l.defaultVerbosity, logWarning, format, a...)
+	l.logf(l.defaultVerbosity, WarningLevel, format, a...)
 }
 
 // Errorf implements the Logger interface.
 func (l *logger) Errorf(format string, a ...interface{}) {
-	l.logf(l.defaultVerbosity, logError, format, a...)
+	l.logf(l.defaultVerbosity, ErrorLevel, format, a...)
 }
 
 // Fatalf implements the Logger interface.
 func (l *logger) Fatalf(format string, a ...interface{}) {
 	// Verbosity level is 0 because we always log fatal messages.
-	l.logf(0, logFatal, format, a...)
+	l.fatal(l.logf(0, FatalLevel, format, a...))
 }
 
 // SetDefaultVerbosity implements the Logger interface.
@@ -306,42 +1469,366 @@ func (l *logger) SetDefaultVerbosity(v int) {
 	l.defaultVerbosity = v
 }
 
+// SetFatalBehavior implements the Logger interface.
+func (l *logger) SetFatalBehavior(b FatalBehavior) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.fatalBehavior = b
+}
+
+// SetFatalHandler implements the Logger interface.
+func (l *logger) SetFatalHandler(h func(msg string)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.fatalHandler = h
+	l.fatalBehavior = FatalCustom
+}
+
+// SetFatalTimeout implements the Logger interface.
+func (l *logger) SetFatalTimeout(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if d < 0 {
+		d = 0
+	}
+	l.fatalTimeout = d
+}
+
+// SetFatalExitCode implements the Logger interface.
+func (l *logger) SetFatalExitCode(code int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.fatalExitCode = code
+}
+
+// AddFatalHook implements the Logger interface.
+func (l *logger) AddFatalHook(hook func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.fatalHooks = append(l.fatalHooks, hook)
+}
+
+// SetFatalHookTimeout implements the Logger interface.
+func (l *logger) SetFatalHookTimeout(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if d < 0 {
+		d = 0
+	}
+	l.fatalHookTimeout = d
+}
+
+// SetFatalStacks implements the Logger interface.
+func (l *logger) SetFatalStacks(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.fatalStacks = enabled
+}
+
+// Go implements the Logger interface.
+func (l *logger) Go(f func()) {
+	go func() {
+		defer l.Recover()
+		f()
+	}()
+}
+
+// Recover implements the Logger interface.
+func (l *logger) Recover() {
+	if r := recover(); r != nil {
+		l.logPanic(r)
+	}
+}
+
+// CheckErr implements the Logger interface.
+func (l *logger) CheckErr(err error, msg string) bool {
+	if err == nil {
+		return false
+	}
+	l.logf(l.defaultVerbosity, ErrorLevel, "%s: %v", msg, err)
+	return true
+}
+
+// Assert implements the Logger interface.
+func (l *logger) Assert(cond bool, format string, a ...interface{}) {
+	if cond {
+		return
+	}
+
+	l.mu.Lock()
+	production := l.production
+	l.mu.Unlock()
+
+	format = "assertion failed: " + format
+	if production {
+		l.logf(0, ErrorLevel, format, a...)
+		return
+	}
+	l.fatal(l.logf(0, FatalLevel, format, a...))
+}
+
+// SetProduction implements the Logger interface.
+func (l *logger) SetProduction(production bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.production = production
+}
+
+// SetDevelopment implements the Logger interface.
+func (l *logger) SetDevelopment(development bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.development = development
+}
+
+// SetRedactKeys implements the Logger interface.
+func (l *logger) SetRedactKeys(keys ...string) {
+	redactor := newRedactor(keys)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.redactor = redactor
+}
+
+// SetScrubRules implements the Logger interface.
+func (l *logger) SetScrubRules(rules ...ScrubRule) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.scrubRules = append([]ScrubRule(nil), rules...)
+}
+
+// AddScrubber implements the Logger interface.
+func (l *logger) AddScrubber(s Scrubber) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.scrubbers = append(l.scrubbers, s)
+}
+
+// SetMaxMessageSize implements the Logger interface.
+func (l *logger) SetMaxMessageSize(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if n < 0 {
+		n = 0
+	}
+	l.maxMessageSize = n
+}
+
+// SetSanitizeMode implements the Logger interface.
+func (l *logger) SetSanitizeMode(mode SanitizeMode) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sanitizeMode = mode
+}
+
+// SetDuplicateKeyPolicy implements the Logger interface.
+func (l *logger) SetDuplicateKeyPolicy(policy DuplicateKeyPolicy) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.duplicateKeys = policy
+}
+
+// SetTimestampGranularity implements the Logger interface.
+func (l *logger) SetTimestampGranularity(granularity time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.timestampGranularity = granularity
+	l.timestampText = ""
+}
+
+// formatTimestamp returns the text timestamp of t, reusing the last one formatted if t is in the
+// same granularity interval. It must be called with l.mu held.
+func (l *logger) formatTimestamp(t time.Time) string {
+	g := l.timestampGranularity
+	if g <= 0 {
+		return t.String()
+	}
+	interval := t.UnixNano() / int64(g)
+	if l.timestampText == "" || interval != l.timestampInterval {
+		// Strip the monotonic clock reading, which would otherwise be frozen with the rest.
+		l.timestampText = t.Round(0).Truncate(g).String()
+		l.timestampInterval = interval
+	}
+	return l.timestampText
+}
+
+// SetSeverityMapping implements the Logger interface.
+func (l *logger) SetSeverityMapping(f SeverityFunc) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.severity = f
+}
+
+// SetFormat implements the Logger interface.
+func (l *logger) SetFormat(format Format) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.format = format
+}
+
+// SetServiceName implements the Logger interface.
+func (l *logger) SetServiceName(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.serviceName = name
+}
+
+// SetCEFOptions implements the Logger interface.
+func (l *logger) SetCEFOptions(opts CEFOptions) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.cefOptions = opts
+}
+
+// SetMultilineMarkers implements the Logger interface.
+func (l *logger) SetMultilineMarkers(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.multilineMarkers = enabled
+}
+
+// AddWriter implements the Logger interface.
+func (l *logger) AddWriter(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.writers = append(l.writers, w)
+}
+
+// RemoveWriter implements the Logger interface.
+func (l *logger) RemoveWriter(w io.Writer) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, existing := range l.writers {
+		if sameWriter(existing, w) {
+			// Build a new slice, as children of the logger may still be writing to the old one.
+			l.writers = append(l.writers[:i:i], l.writers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// SetOutput implements the Logger interface.
+func (l *logger) SetOutput(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.writers = nil
+	if w != nil {
+		l.writers = []io.Writer{w}
+	}
+	l.ownWriters = true
+}
+
+// ReplaceWriter implements the Logger interface.
+func (l *logger) ReplaceWriter(old, new io.Writer) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, existing := range l.writers {
+		if sameWriter(existing, old) {
+			// Build a new slice, as children of the logger may still be writing to the old one.
+			l.writers = append([]io.Writer(nil), l.writers...)
+			l.writers[i] = new
+			return true
+		}
+	}
+	return false
+}
+
+// sameWriter reports whether a and b are the same writer. Writers of types that can't be compared
+// are never the same.
+func sameWriter(a, b io.Writer) bool {
+	t := reflect.TypeOf(a)
+	return t == reflect.TypeOf(b) && t != nil && t.Comparable() && a == b
+}
+
+// SetRecoverFatal implements the Logger interface.
+func (l *logger) SetRecoverFatal(fatal bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.recoverFatal = fatal
+}
+
 // SetVerbosity implements the Logger interface.
 func (l *logger) SetVerbosity(v int) {
 	l.mu.Lock()
-	defer l.mu.Unlock()
+	old := l.inheritedVerbosity()
+	l.verbosity, l.ownVerbosity = v, true
+	l.mu.Unlock()
+
+	if v != old {
+		l.logVerbosityChange(old, v)
+	}
+}
+
+// logVerbosityChange records a change of the logger's verbosity from old to v on behalf of
+// SetVerbosity, naming the function that made the change. The record is written whatever the
+// verbosity, so that sudden silence or noise in the logs can be explained.
+func (l *logger) logVerbosityChange(old, v int) {
+	by := "unknown"
+	// Skip logVerbosityChange and SetVerbosity, plus the package-level function for the default
+	// logger.
+	if site, ok := callerFrame(l.callerSkip - 1); ok && site.function != "" {
+		by = site.function
+	}
 
-	l.verbosity = v
+	fields := []Field{{Key: "old", Value: old}, {Key: "new", Value: v}, {Key: "by", Value: by}}
+	l.logwDepth(l.callerSkip, math.MinInt, InfoLevel, "verbosity changed", fields)
 }
 
 // VInfo implements the Logger interface.
 func (l *logger) VInfo(verbosity int, a ...interface{}) {
-	l.log(verbosity, logInfo, a...)
+	l.log(verbosity, InfoLevel, a...)
 }
 
 // VWarning implements the Logger interface.
 func (l *logger) VWarning(verbosity int, a ...interface{}) {
-	l.log(verbosity, logWarning, a...)
+	l.log(verbosity, WarningLevel, a...)
 }
 
 // VError implements the Logger interface.
 func (l *logger) VError(verbosity int, a ...interface{}) {
-	l.log(verbosity, logError, a...)
+	l.log(verbosity, ErrorLevel, a...)
 }
 
 // VInfof implements the Logger interface.
 func (l *logger) VInfof(verbosity int, format string, a ...interface{}) {
-	l.logf(verbosity, logInfo, format, a...)
+	l.logf(verbosity, InfoLevel, format, a...)
 }
 
 // VWarningf implements the Logger interface.
 func (l *logger) VWarningf(verbosity int, format string, a ...interface{}) {
-	l.logf(verbosity, logWarning, format, a...)
+	l.logf(verbosity, WarningLevel, format, a...)
 }
 
 // VErrorf implements the Logger interface.
 func (l *logger) VErrorf(verbosity int, format string, a ...interface{}) {
-	l.logf(verbosity, logError, format, a...)
+	l.logf(verbosity, ErrorLevel, format, a...)
 }
 
 // Default logger convenience functions
@@ -351,6 +1838,16 @@ func Info(a ...interface{}) {
 	defaultLogger.Info(a...)
 }
 
+// Print is a convenience method that calls defaultLogger.Print(a..)
+func Print(a ...interface{}) {
+	defaultLogger.Print(a...)
+}
+
+// Println is a convenience method that calls defaultLogger.Println(a..)
+func Println(a ...interface{}) {
+	defaultLogger.Println(a...)
+}
+
 // Warning is a convenience method that calls defaultLogger.Warning(a..)
 func Warning(a ...interface{}) {
 	defaultLogger.Warning(a...)
@@ -366,11 +1863,26 @@ func Fatal(a ...interface{}) {
 	defaultLogger.Fatal(a...)
 }
 
+// FatalCode is a convenience method that calls defaultLogger.FatalCode(code, a...)
+func FatalCode(code int, a ...interface{}) {
+	defaultLogger.FatalCode(code, a...)
+}
+
+// AddFatalHook is a convenience method that calls defaultLogger.AddFatalHook(hook)
+func AddFatalHook(hook func()) {
+	defaultLogger.AddFatalHook(hook)
+}
+
 // Infof is a convenience method that calls defaultLogger.Infof(format, a..)
 func Infof(format string, a ...interface{}) {
 	defaultLogger.Infof(format, a...)
 }
 
+// Printf is a convenience method that calls defaultLogger.Printf(format, a..)
+func Printf(format string, a ...interface{}) {
+	defaultLogger.Printf(format, a...)
+}
+
 // Warningf is a convenience method that calls defaultLogger.Warningf(format, a..)
 func Warningf(format string, a ...interface{}) {
 	defaultLogger.Warningf(format, a...)
@@ -415,3 +1927,93 @@ func VWarningf(verbosity int, format string, a ...interface{}) {
 func VErrorf(verbosity int, format string, a ...interface{}) {
 	defaultLogger.VErrorf(verbosity, format, a...)
 }
+
+// CheckErr is a convenience method that calls defaultLogger.CheckErr(err, msg)
+func CheckErr(err error, msg string) bool {
+	return defaultLogger.CheckErr(err, msg)
+}
+
+// Assert is a convenience method that calls defaultLogger.Assert(cond, format, a...)
+func Assert(cond bool, format string, a ...interface{}) {
+	defaultLogger.Assert(cond, format, a...)
+}
+
+// Must returns v if err is nil. Otherwise it logs err as a fatal message using the default logger,
+// which doesn't return unless the logger is configured with a fatal handler that returns.
+func Must[T any](v T, err error) T {
+	if err != nil {
+		// Skip logfDepth and Must to attribute the message to Must's caller.
+		defaultLogger.fatal(defaultLogger.logfDepth(2, 0, FatalLevel, "%v", err))
+	}
+	return v
+}
+
+// SetVerbosity is a convenience method that calls defaultLogger.SetVerbosity(v)
+func SetVerbosity(v int) {
+	defaultLogger.SetVerbosity(v)
+}
+
+// SetDefaultVerbosity is a convenience method that calls defaultLogger.SetDefaultVerbosity(v)
+func SetDefaultVerbosity(v int) {
+	defaultLogger.SetDefaultVerbosity(v)
+}
+
+// AddWriter is a convenience method that calls defaultLogger.AddWriter(w)
+func AddWriter(w io.Writer) {
+	defaultLogger.AddWriter(w)
+}
+
+// RemoveWriter is a convenience method that calls defaultLogger.RemoveWriter(w)
+func RemoveWriter(w io.Writer) bool {
+	return defaultLogger.RemoveWriter(w)
+}
+
+// SetOutput is a convenience method that calls defaultLogger.SetOutput(w)
+func SetOutput(w io.Writer) {
+	defaultLogger.SetOutput(w)
+}
+
+// SetColorful is a convenience method that calls defaultLogger.SetColorful(colorful)
+func SetColorful(colorful bool) {
+	defaultLogger.SetColorful(colorful)
+}
+
+// SetTimestamp is a convenience method that calls defaultLogger.SetTimestamp(timestamp)
+func SetTimestamp(timestamp bool) {
+	defaultLogger.SetTimestamp(timestamp)
+}
+
+// SetSplitConsole is a convenience method that calls defaultLogger.SetSplitConsole(split)
+func SetSplitConsole(split bool) {
+	defaultLogger.SetSplitConsole(split)
+}
+
+// SetStderrEnabled is a convenience method that calls defaultLogger.SetStderrEnabled(enabled)
+func SetStderrEnabled(enabled bool) {
+	defaultLogger.SetStderrEnabled(enabled)
+}
+
+// SetFileEnabled is a convenience method that calls defaultLogger.SetFileEnabled(enabled)
+func SetFileEnabled(enabled bool) {
+	defaultLogger.SetFileEnabled(enabled)
+}
+
+// Flush is a convenience method that calls defaultLogger.Flush()
+func Flush() error {
+	return defaultLogger.Flush()
+}
+
+// Go is a convenience method that calls defaultLogger.Go(f)
+func Go(f func()) {
+	defaultLogger.Go(f)
+}
+
+// Recover is the package-level equivalent of defaultLogger.Recover(). Like the method, it must be
+// called directly by a deferred function.
+func Recover() {
+	// recover only stops a panic when called directly by the deferred function, so this can't
+	// simply call defaultLogger.Recover().
+	if r := recover(); r != nil {
+		defaultLogger.logPanic(r)
+	}
+}
@@ -0,0 +1,156 @@
+// Package logparse parses the text output of package log back into records, so that tools, tests
+// and migration scripts can consume existing log files.
+package logparse
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crunchyroll/multilog/log"
+)
+
+// Record is a record parsed from a text log.
+type Record struct {
+	// Time is the timestamp of the record, or the zero time if the logger didn't write timestamps.
+	Time  time.Time
+	Level log.Level
+	// Count is the sequence number of the record within its level, or -1 for fatal records, which
+	// aren't numbered.
+	Count int64
+	// Component is the component path of a named logger, or empty.
+	Component string
+	File      string
+	Line      int
+	// Message is the message of the record, including any fields and continuation lines.
+	Message string
+	// Raw is the text of the record as it was read, without its final newline.
+	Raw string
+}
+
+// timeLayout is the layout of timestamps written by loggers, as formatted by time.Time.String.
+const timeLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+// header matches the first line of a text record.
+var header = regexp.MustCompile(`^(?:(\d{4}-\d\d-\d\d \d\d:\d\d:\d\d(?:\.\d+)? [+-]\d{4} \S+)` +
+	`(?: m=[+-]\d+\.\d+)? )?\[(FATAL|[A-Z]\d{4,})(?: ([^\]]*))?\] (.*?):(\d+): (.*)$`)
+
+// continuation matches continuation lines marked by loggers configured with SetMultilineMarkers.
+var continuation = regexp.MustCompile(`^  \|(?:FATAL|[A-Z]\d{4,})\| (.*)$`)
+
+// ansiColor matches the color escape sequences written to stderr by colorful loggers.
+var ansiColor = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// levels maps the tag prefixes of text records to their levels.
+var levels = map[string]log.Level{
+	"I":     log.InfoLevel,
+	"W":     log.WarningLevel,
+	"E":     log.ErrorLevel,
+	"FATAL": log.FatalLevel,
+	"A":     log.AuditLevel,
+}
+
+// ErrNotRecord is returned by Parse for lines that don't start a record.
+var ErrNotRecord = errors.New("logparse: not a log record")
+
+// Parse parses a single line of a text log. It returns ErrNotRecord if the line doesn't start a
+// record, for example because it is the continuation of a multi-line record.
+func Parse(line string) (Record, error) {
+	line = ansiColor.ReplaceAllString(strings.TrimRight(line, "\r\n"), "")
+	m := header.FindStringSubmatch(line)
+	if m == nil {
+		return Record{}, ErrNotRecord
+	}
+
+	r := Record{Count: -1, Component: m[3], File: m[4], Message: m[6], Raw: line}
+	if m[1] != "" {
+		t, err := time.Parse(timeLayout, m[1])
+		if err != nil {
+			return Record{}, fmt.Errorf("logparse: invalid timestamp: %w", err)
+		}
+		r.Time = t
+	}
+
+	tag := m[2]
+	if tag != "FATAL" {
+		r.Count, _ = strconv.ParseInt(tag[1:], 10, 64)
+		tag = tag[:1]
+	}
+	level, ok := levels[tag]
+	if !ok {
+		return Record{}, fmt.Errorf("logparse: unknown level %q", tag)
+	}
+	r.Level = level
+	r.Line, _ = strconv.Atoi(m[5])
+	return r, nil
+}
+
+// Reader reads records from a text log.
+type Reader struct {
+	r      *bufio.Reader
+	lineNo int
+	// next is the record started by the last line read, if any.
+	next *Record
+}
+
+// NewReader returns a Reader reading records from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next record, joining continuation lines to the message of their record. It
+// returns io.EOF when there are no more records. Lines before the first record are an error.
+func (r *Reader) Next() (Record, error) {
+	for {
+		line, err := r.r.ReadString('\n')
+		if line == "" && err != nil {
+			if err == io.EOF && r.next != nil {
+				rec := *r.next
+				r.next = nil
+				return rec, nil
+			}
+			return Record{}, err
+		}
+		r.lineNo++
+
+		rec, perr := Parse(line)
+		switch {
+		case perr == nil:
+			if prev := r.next; prev != nil {
+				r.next = &rec
+				return *prev, nil
+			}
+			r.next = &rec
+		case errors.Is(perr, ErrNotRecord) && r.next != nil:
+			line = ansiColor.ReplaceAllString(strings.TrimRight(line, "\r\n"), "")
+			r.next.Raw += "\n" + line
+			if m := continuation.FindStringSubmatch(line); m != nil {
+				line = m[1]
+			}
+			r.next.Message += "\n" + line
+		default:
+			return Record{}, fmt.Errorf("line %d: %w", r.lineNo, perr)
+		}
+	}
+}
+
+// ReadAll reads all records from r.
+func ReadAll(r io.Reader) ([]Record, error) {
+	var records []Record
+	rr := NewReader(r)
+	for {
+		rec, err := rr.Next()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, rec)
+	}
+}
@@ -0,0 +1,115 @@
+package logparse
+
+import (
+	"container/heap"
+	"io"
+	"time"
+)
+
+// Merger merges the records of several text logs, such as the per-process files created by
+// log.Init, into a single chronologically ordered stream. Records are ordered by timestamp; each
+// log is assumed to be in order already, so records of the same log keep their order, and records
+// without a timestamp are placed right after the previous record of their log. Records with equal
+// timestamps are ordered by the position of their log in the arguments of NewMerger.
+type Merger struct {
+	readers []*Reader
+	heads   mergeHeap
+	started bool
+}
+
+// NewMerger returns a Merger merging the records read from logs.
+func NewMerger(logs ...io.Reader) *Merger {
+	m := &Merger{}
+	for _, r := range logs {
+		m.readers = append(m.readers, NewReader(r))
+	}
+	return m
+}
+
+// mergeHead is the next record of one of the logs being merged.
+type mergeHead struct {
+	rec    Record
+	source int
+	// time used to order the record, which is that of the previous record if it has none.
+	time time.Time
+}
+
+// mergeHeap is a min-heap of the next records of the logs being merged.
+type mergeHeap []mergeHead
+
+func (h mergeHeap) Len() int { return len(h) }
+
+func (h mergeHeap) Less(i, j int) bool {
+	if !h[i].time.Equal(h[j].time) {
+		return h[i].time.Before(h[j].time)
+	}
+	return h[i].source < h[j].source
+}
+
+func (h mergeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *mergeHeap) Push(x interface{}) { *h = append(*h, x.(mergeHead)) }
+
+func (h *mergeHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// Next returns the next record in chronological order and the index of the log it was read from.
+// It returns io.EOF once all logs are exhausted.
+func (m *Merger) Next() (Record, int, error) {
+	if !m.started {
+		m.started = true
+		for i := range m.readers {
+			if err := m.advance(i, time.Time{}); err != nil {
+				return Record{}, i, err
+			}
+		}
+	}
+	if m.heads.Len() == 0 {
+		return Record{}, 0, io.EOF
+	}
+
+	head := heap.Pop(&m.heads).(mergeHead)
+	if err := m.advance(head.source, head.time); err != nil {
+		return Record{}, head.source, err
+	}
+	return head.rec, head.source, nil
+}
+
+// advance reads the next record of log i onto the heap, ordering it at prev if it has no
+// timestamp.
+func (m *Merger) advance(i int, prev time.Time) error {
+	rec, err := m.readers[i].Next()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	t := rec.Time
+	if t.IsZero() {
+		t = prev
+	}
+	heap.Push(&m.heads, mergeHead{rec: rec, source: i, time: t})
+	return nil
+}
+
+// Merge writes the records of logs to w in chronological order, as described for Merger.
+func Merge(w io.Writer, logs ...io.Reader) error {
+	m := NewMerger(logs...)
+	for {
+		rec, _, err := m.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, rec.Raw+"\n"); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// truncate shortens s to at most max bytes, not counting the marker noting how many bytes were
+// removed. It never splits a UTF-8 encoded character. A max <= 0 means no limit.
+func truncate(s string, max int) string {
+	if max <= 0 || len(s) <= max {
+		return s
+	}
+
+	n := max
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return fmt.Sprintf("%s...[truncated %d bytes]", s[:n], len(s)-n)
+}
+
+// SanitizeMode determines how control characters in messages are handled.
+type SanitizeMode int
+
+const (
+	// SanitizeNone writes messages as they are. This is the default.
+	SanitizeNone SanitizeMode = iota
+	// SanitizeEscape replaces control characters, including newlines but not tabs, with Go escape
+	// sequences such as \n and \x1b, so that every message occupies a single line.
+	SanitizeEscape
+	// SanitizeStrip removes control characters other than tabs. Line breaks are replaced with
+	// spaces so that the words on either side stay apart.
+	SanitizeStrip
+)
+
+// String returns the name of the mode, such as "escape".
+func (mode SanitizeMode) String() string {
+	switch mode {
+	case SanitizeNone:
+		return "none"
+	case SanitizeEscape:
+		return "escape"
+	case SanitizeStrip:
+		return "strip"
+	}
+	return fmt.Sprintf("SanitizeMode(%d)", int(mode))
+}
+
+// sanitize handles the control characters in s according to mode.
+func sanitize(s string, mode SanitizeMode) string {
+	if mode == SanitizeNone || !needsSanitizing(s) {
+		return s
+	}
+
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			if mode == SanitizeEscape {
+				b = fmt.Appendf(b, `\x%02x`, s[i])
+			}
+		case !isControl(r):
+			b = append(b, s[i:i+size]...)
+		case mode == SanitizeStrip:
+			if r == '\n' || r == '\r' || r == '\u2028' || r == '\u2029' {
+				b = append(b, ' ')
+			}
+		case r == '\n':
+			b = append(b, `\n`...)
+		case r == '\r':
+			b = append(b, `\r`...)
+		case r < 0x80:
+			b = fmt.Appendf(b, `\x%02x`, r)
+		default:
+			b = fmt.Appendf(b, `\u%04x`, r)
+		}
+		i += size
+	}
+	return string(b)
+}
+
+// needsSanitizing reports whether s contains control characters or invalid UTF-8.
+func needsSanitizing(s string) bool {
+	for _, r := range s {
+		if isControl(r) || r == utf8.RuneError {
+			return true
+		}
+	}
+	return false
+}
+
+// isControl reports whether r is a control character that sanitizing should remove. Tabs are
+// allowed, but Unicode line and paragraph separators are not.
+func isControl(r rune) bool {
+	return r != '\t' && (r < 0x20 || (r >= 0x7f && r < 0xa0) || r == '\u2028' || r == '\u2029')
+}
+
+// markContinuationLines prefixes every line of the record s after the first with a marker holding
+// the record's tag.
+func markContinuationLines(s, tag string) string {
+	if !strings.Contains(s, "\n") {
+		return s
+	}
+	return strings.ReplaceAll(s, "\n", "\n  |"+tag+"| ")
+}
@@ -0,0 +1,203 @@
+package log
+
+import (
+	"sync"
+	"time"
+)
+
+// Kinds of metric records, in their "type" field.
+const (
+	metricCounter = "counter"
+	metricGauge   = "gauge"
+)
+
+// metricKey identifies a series of metric records aggregated together: those of a logger with the
+// same name, kind and call-site fields.
+type metricKey struct {
+	l      *logger
+	name   string
+	kind   string
+	fields string
+}
+
+// metricValue is the aggregated value of a series, and the call site of its first record, to which
+// the aggregated record is attributed.
+type metricValue struct {
+	count  int64
+	gauge  float64
+	fields []Field
+	file   string
+	line   int
+	ok     bool
+}
+
+// metricAggregator aggregates metric records in process and logs them periodically. It is shared
+// by clones of the logger it was set up on, and has its own lock for this reason. Only that logger
+// stops it.
+type metricAggregator struct {
+	mu       sync.Mutex
+	pending  map[metricKey]*metricValue
+	order    []metricKey
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// newMetricAggregator returns a metricAggregator logging aggregated records every interval.
+func newMetricAggregator(interval time.Duration) *metricAggregator {
+	a := &metricAggregator{
+		pending: map[metricKey]*metricValue{},
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go a.run(interval)
+	return a
+}
+
+// run logs the aggregated records every interval until the aggregator is stopped, and once more
+// when it is.
+func (a *metricAggregator) run(interval time.Duration) {
+	defer close(a.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			a.flush()
+		case <-a.stop:
+			a.flush()
+			return
+		}
+	}
+}
+
+// close stops the aggregator once its pending records have been logged.
+func (a *metricAggregator) close() {
+	a.stopOnce.Do(func() { close(a.stop) })
+	<-a.done
+}
+
+// add aggregates a record into its series: counter deltas are summed, and the last gauge value
+// wins.
+func (a *metricAggregator) add(key metricKey, delta int64, value float64, fields []Field,
+	file string, line int, ok bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	v := a.pending[key]
+	if v == nil {
+		v = &metricValue{fields: fields, file: file, line: line, ok: ok}
+		a.pending[key] = v
+		a.order = append(a.order, key)
+	}
+	v.count += delta
+	v.gauge = value
+}
+
+// flush logs the aggregated record of every series seen since the last flush, in the order the
+// series were first seen.
+func (a *metricAggregator) flush() {
+	a.mu.Lock()
+	pending, order := a.pending, a.order
+	a.pending, a.order = map[metricKey]*metricValue{}, nil
+	a.mu.Unlock()
+
+	for _, key := range order {
+		v := pending[key]
+		var value interface{} = v.count
+		if key.kind == metricGauge {
+			value = v.gauge
+		}
+		key.l.mu.Lock()
+		if key.l.defaultVerbosity <= key.l.inheritedVerbosity() {
+			key.l.write(InfoLevel, key.l.defaultVerbosity, key.name,
+				metricFields(key.name, key.kind, value, v.fields), v.file, v.line, v.ok)
+		}
+		key.l.mu.Unlock()
+	}
+}
+
+// metricFields returns the fields of a metric record: its name, kind and value, followed by
+// fields.
+func metricFields(name, kind string, value interface{}, fields []Field) []Field {
+	return append([]Field{
+		{Key: "metric", Value: name},
+		{Key: "type", Value: kind},
+		{Key: "value", Value: value},
+	}, fields...)
+}
+
+// Count implements the Logger interface.
+func (l *logger) Count(name string, delta int64, fields ...Field) {
+	l.metric(name, metricCounter, delta, 0, fields)
+}
+
+// Gauge implements the Logger interface.
+func (l *logger) Gauge(name string, value float64, fields ...Field) {
+	l.metric(name, metricGauge, 0, value, fields)
+}
+
+// metric logs or aggregates a metric record for Count or Gauge.
+func (l *logger) metric(name, kind string, delta int64, value float64, fields []Field) {
+	l.mu.Lock()
+	metrics := l.metrics
+	l.mu.Unlock()
+
+	if metrics == nil {
+		var v interface{} = delta
+		if kind == metricGauge {
+			v = value
+		}
+		// Skip logwDepth, metric and Count or Gauge, plus the package-level function for the
+		// default logger.
+		l.logwDepth(l.callerSkip, l.defaultVerbosity, InfoLevel, name,
+			metricFields(name, kind, v, fields))
+		return
+	}
+
+	file, line, ok := caller(l.callerSkip - 1)
+	key := metricKey{l: l, name: name, kind: kind, fields: formatFields(fields)}
+	metrics.add(key, delta, value, append([]Field(nil), fields...), file, line, ok)
+}
+
+// SetMetricInterval implements the Logger interface.
+func (l *logger) SetMetricInterval(interval time.Duration) {
+	l.mu.Lock()
+	old, owned := l.metrics, l.ownMetrics
+	l.metrics, l.ownMetrics = nil, false
+	if interval > 0 {
+		l.metrics, l.ownMetrics = newMetricAggregator(interval), true
+	}
+	l.mu.Unlock()
+
+	// An aggregator inherited from the logger a clone was made from keeps running for that logger.
+	if old != nil && owned {
+		old.close()
+	}
+}
+
+// flushMetrics logs the records aggregated by l, if any.
+func (l *logger) flushMetrics() {
+	l.mu.Lock()
+	metrics := l.metrics
+	l.mu.Unlock()
+
+	if metrics != nil {
+		metrics.flush()
+	}
+}
+
+// Count is a convenience method that calls defaultLogger.Count(name, delta, fields...)
+func Count(name string, delta int64, fields ...Field) {
+	defaultLogger.Count(name, delta, fields...)
+}
+
+// Gauge is a convenience method that calls defaultLogger.Gauge(name, value, fields...)
+func Gauge(name string, value float64, fields ...Field) {
+	defaultLogger.Gauge(name, value, fields...)
+}
+
+// SetMetricInterval is a convenience method that calls defaultLogger.SetMetricInterval(interval)
+func SetMetricInterval(interval time.Duration) {
+	defaultLogger.SetMetricInterval(interval)
+}
@@ -0,0 +1,35 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetMetricIntervalOnClone(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger(false, false, false, &buf)
+	l.SetMetricInterval(time.Hour)
+	c := l.Clone()
+	// Turning metrics off on the clone must leave the aggregator of l alone.
+	c.SetMetricInterval(0)
+
+	l.Count("requests", 2)
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); !strings.Contains(got, "metric=requests type=counter value=2") {
+		t.Errorf("aggregated record not written after the clone turned metrics off, got %q", got)
+	}
+
+	buf.Reset()
+	c.Count("retries", 1)
+	if got := buf.String(); !strings.Contains(got, "metric=retries type=counter value=1") {
+		t.Errorf("clone without metric aggregation didn't write its record, got %q", got)
+	}
+
+	// Stopping the aggregator of l used to close it a second time and panic.
+	l.SetMetricInterval(0)
+	c.SetMetricInterval(0)
+}
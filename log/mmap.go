@@ -0,0 +1,203 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultMmapChunkSize is the size by which an MmapWriter grows its file when it runs out of
+// preallocated space.
+const DefaultMmapChunkSize = 16 << 20
+
+// MmapWriter writes records to a file through a memory mapping instead of write system calls, for
+// programs logging so much that even batched writes are a bottleneck. The file is preallocated in
+// chunks and synced to disk periodically and on Close, which truncates it to the records actually
+// written.
+//
+// If the program crashes, records written since the last sync may be lost, and the file is left
+// with preallocated zeros after the last record, possibly preceded by a partial record. Opening the
+// file again with OpenMmapWriter truncates it after the last complete record, so this only works
+// with newline-terminated formats: FormatText, FormatOTel and FormatCEF.
+//
+// MmapWriter is only supported on Linux and macOS.
+type MmapWriter struct {
+	mu    sync.Mutex
+	f     *os.File
+	chunk int64
+	// data maps the window of the file starting at base. pos is the offset at which the next record
+	// is written.
+	data []byte
+	base int64
+	pos  int64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// OpenMmapWriter opens the file name for writing through a memory mapping, creating it if
+// necessary, and syncs it to disk every syncInterval. Records are appended after the last complete
+// record already in the file. A chunkSize of zero uses DefaultMmapChunkSize, and a syncInterval of
+// zero only syncs on Close.
+func OpenMmapWriter(name string, chunkSize int64, syncInterval time.Duration) (*MmapWriter, error) {
+	if chunkSize <= 0 {
+		chunkSize = DefaultMmapChunkSize
+	}
+	// Windows must start at page boundaries, so make them a whole number of pages.
+	page := int64(os.Getpagesize())
+	chunkSize = (chunkSize + page - 1) / page * page
+
+	f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE, DefaultFileMode)
+	if err != nil {
+		return nil, err
+	}
+	end, err := lastRecordEnd(f)
+	if err == nil {
+		err = f.Truncate(end)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	m := &MmapWriter{f: f, chunk: chunkSize, pos: end, stop: make(chan struct{}), done: make(chan struct{})}
+	if err := m.remap(end); err != nil {
+		f.Close()
+		return nil, err
+	}
+	go m.syncEvery(syncInterval)
+	return m, nil
+}
+
+// lastRecordEnd returns the offset just after the last newline of f that is followed by nothing but
+// zeros or partial records, which is where appending should resume after a crash.
+func lastRecordEnd(f *os.File) (int64, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	buf := make([]byte, 64<<10)
+	for end := info.Size(); end > 0; {
+		start := end - int64(len(buf))
+		if start < 0 {
+			start = 0
+		}
+		block := buf[:end-start]
+		if _, err := f.ReadAt(block, start); err != nil && err != io.EOF {
+			return 0, err
+		}
+		if i := bytes.LastIndexByte(block, '\n'); i >= 0 {
+			return start + int64(i) + 1, nil
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+// remap maps the window of the file containing off, growing the file if necessary. It must be
+// called with m.mu held.
+func (m *MmapWriter) remap(off int64) error {
+	if m.data != nil {
+		msync(m.data)
+		if err := munmap(m.data); err != nil {
+			return err
+		}
+		m.data = nil
+	}
+
+	base := off / m.chunk * m.chunk
+	info, err := m.f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < base+m.chunk {
+		if err := m.f.Truncate(base + m.chunk); err != nil {
+			return err
+		}
+	}
+	data, err := mmap(m.f, base, int(m.chunk))
+	if err != nil {
+		return err
+	}
+	m.data, m.base = data, base
+	return nil
+}
+
+// Write copies p into the mapped file, growing it as needed.
+func (m *MmapWriter) Write(p []byte) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.f == nil {
+		return 0, ErrWriterClosed
+	}
+	written := 0
+	for written < len(p) {
+		if m.pos >= m.base+int64(len(m.data)) {
+			if err := m.remap(m.pos); err != nil {
+				return written, err
+			}
+		}
+		n := copy(m.data[m.pos-m.base:], p[written:])
+		m.pos += int64(n)
+		written += n
+	}
+	return written, nil
+}
+
+// Flush syncs the records written so far to disk.
+func (m *MmapWriter) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.data == nil {
+		return nil
+	}
+	return msync(m.data)
+}
+
+// syncEvery flushes m every interval until it is closed.
+func (m *MmapWriter) syncEvery(interval time.Duration) {
+	defer close(m.done)
+	if interval <= 0 {
+		<-m.stop
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.Flush()
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+// Close syncs the file, truncates it after the last record and closes it.
+func (m *MmapWriter) Close() error {
+	m.mu.Lock()
+	if m.f == nil {
+		m.mu.Unlock()
+		return nil
+	}
+	close(m.stop)
+	err := msync(m.data)
+	if uerr := munmap(m.data); err == nil {
+		err = uerr
+	}
+	if terr := m.f.Truncate(m.pos); err == nil {
+		err = terr
+	}
+	if cerr := m.f.Close(); err == nil {
+		err = cerr
+	}
+	m.f, m.data = nil, nil
+	m.mu.Unlock()
+
+	<-m.done
+	return err
+}
@@ -0,0 +1,26 @@
+//go:build !linux && !darwin
+
+package log
+
+import (
+	"errors"
+	"os"
+)
+
+// errMmapUnsupported is returned by OpenMmapWriter on platforms without memory-mapped files.
+var errMmapUnsupported = errors.New("memory-mapped log files are not supported on this platform")
+
+// mmap maps length bytes of f starting at offset, which isn't supported on this platform.
+func mmap(f *os.File, offset int64, length int) ([]byte, error) {
+	return nil, errMmapUnsupported
+}
+
+// munmap unmaps data mapped by mmap.
+func munmap(data []byte) error {
+	return errMmapUnsupported
+}
+
+// msync writes the changes to data mapped by mmap to disk.
+func msync(data []byte) error {
+	return errMmapUnsupported
+}
@@ -0,0 +1,94 @@
+//go:build linux || darwin
+
+package log
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenMmapWriterRecovery(t *testing.T) {
+	zeros := string(make([]byte, 4096))
+	tests := []struct {
+		name     string
+		existing *string
+		want     string
+	}{
+		{name: "missing file", want: "new\n"},
+		{name: "empty file", existing: ptr(""), want: "new\n"},
+		{name: "complete records", existing: ptr("a\nb\n"), want: "a\nb\nnew\n"},
+		{name: "preallocated zeros", existing: ptr("a\nb\n" + zeros), want: "a\nb\nnew\n"},
+		{name: "partial trailing record", existing: ptr("a\nb"), want: "a\nnew\n"},
+		{name: "partial record before zeros", existing: ptr("a\nbc" + zeros), want: "a\nnew\n"},
+		{name: "zeros only", existing: ptr(zeros), want: "new\n"},
+		{name: "partial record only", existing: ptr("abc"), want: "new\n"},
+		{
+			name:     "partial record longer than a read block",
+			existing: ptr("a\n" + strings.Repeat("x", 100<<10) + zeros),
+			want:     "a\nnew\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "mmap.log")
+			if tt.existing != nil {
+				if err := os.WriteFile(name, []byte(*tt.existing), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			m, err := OpenMmapWriter(name, 0, 0)
+			if err != nil {
+				t.Fatalf("OpenMmapWriter: %v", err)
+			}
+			if _, err := m.Write([]byte("new\n")); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+			if err := m.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			got, err := os.ReadFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, []byte(tt.want)) {
+				t.Errorf("file contains %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMmapWriterGrows(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "mmap.log")
+	m, err := OpenMmapWriter(name, int64(os.Getpagesize()), 0)
+	if err != nil {
+		t.Fatalf("OpenMmapWriter: %v", err)
+	}
+	var want bytes.Buffer
+	line := []byte(strings.Repeat("x", 999) + "\n")
+	for i := 0; i < 20; i++ {
+		if _, err := m.Write(line); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		want.Write(line)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	got, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want.Bytes()) {
+		t.Errorf("file has %d bytes, want %d", len(got), want.Len())
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
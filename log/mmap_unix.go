@@ -0,0 +1,31 @@
+//go:build linux || darwin
+
+package log
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// mmap maps length bytes of f starting at offset for reading and writing.
+func mmap(f *os.File, offset int64, length int) ([]byte, error) {
+	return syscall.Mmap(int(f.Fd()), offset, length, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+}
+
+// munmap unmaps data mapped by mmap.
+func munmap(data []byte) error {
+	return syscall.Munmap(data)
+}
+
+// msync writes the changes to data mapped by mmap to disk.
+func msync(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_MSYNC, uintptr(unsafe.Pointer(&data[0])), uintptr(len(data)), syscall.MS_SYNC)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"os"
+	"time"
+)
+
+// NewRelicEndpoint is the New Relic Log API endpoint for accounts in the US region. Accounts in the
+// EU region use https://log-api.eu.newrelic.com/log/v1.
+const NewRelicEndpoint = "https://log-api.newrelic.com/log/v1"
+
+// NewRelicOptions configures a NewRelicWriter.
+type NewRelicOptions struct {
+	// LicenseKey authenticates to the Log API. It defaults to the NEW_RELIC_LICENSE_KEY environment
+	// variable.
+	LicenseKey string
+	// Endpoint is the URL of the Log API. It defaults to NewRelicEndpoint.
+	Endpoint string
+	// EntityGUID and EntityName link records to an APM entity in New Relic. The Go agent provides
+	// them in the metadata returned by Application.GetLinkingMetadata. They are omitted if empty.
+	EntityGUID string
+	EntityName string
+	// Hostname is the host the records come from. It defaults to the name reported by the kernel.
+	Hostname string
+	// Client posts the records. It defaults to a client with a timeout of DefaultHTTPTimeout.
+	Client *http.Client
+	// QueueSize is the number of records that can wait to be posted before records are dropped.
+	// It defaults to 4096.
+	QueueSize int
+	// MaxBatchBytes is the size of the records in a batch above which the batch is posted. It
+	// defaults to 1MB.
+	MaxBatchBytes int
+	// MaxLatency is the time after which a batch is posted regardless of its size. It defaults to
+	// 5 seconds.
+	MaxLatency time.Duration
+}
+
+// NewRelicWriter posts records in batches to the New Relic Log API, with the linking metadata of
+// the program attached to every record. Records written in a JSON format such as FormatOTel are
+// parsed into attributes by New Relic. Records are posted from a goroutine of their own, and are
+// dropped rather than holding up the logger when the API falls behind.
+type NewRelicWriter struct {
+	*httpSink
+	opts   NewRelicOptions
+	common []byte
+}
+
+// NewNewRelicWriter returns a NewRelicWriter configured with opts. It fails if there is no license
+// key.
+func NewNewRelicWriter(opts NewRelicOptions) (*NewRelicWriter, error) {
+	if opts.LicenseKey == "" {
+		opts.LicenseKey = os.Getenv("NEW_RELIC_LICENSE_KEY")
+	}
+	if opts.LicenseKey == "" {
+		return nil, errors.New("no New Relic license key")
+	}
+	if opts.Endpoint == "" {
+		opts.Endpoint = NewRelicEndpoint
+	}
+	if opts.Hostname == "" {
+		opts.Hostname, _ = os.Hostname()
+	}
+
+	w := &NewRelicWriter{opts: opts, common: newRelicCommon(&opts)}
+	w.httpSink = newHTTPSink(opts.Client, w.request, opts.QueueSize, opts.MaxBatchBytes, opts.MaxLatency)
+	return w, nil
+}
+
+// newRelicCommon returns the common block of the payloads posted with opts, holding the linking
+// metadata that is available.
+func newRelicCommon(opts *NewRelicOptions) []byte {
+	var b bytes.Buffer
+	b.WriteString(`{"attributes":{"plugin.type":"multilog"`)
+	for _, attr := range []Field{
+		{Key: "entity.guid", Value: opts.EntityGUID},
+		{Key: "entity.name", Value: opts.EntityName},
+		{Key: "hostname", Value: opts.Hostname},
+	} {
+		if attr.Value != "" {
+			b.WriteByte(',')
+			appendJSON(&b, attr.Key)
+			b.WriteByte(':')
+			appendJSON(&b, attr.Value)
+		}
+	}
+	b.WriteString(`}}`)
+	return b.Bytes()
+}
+
+// request returns the gzip compressed request posting batch to the Log API.
+func (w *NewRelicWriter) request(batch []sinkRecord) (*http.Request, error) {
+	var b bytes.Buffer
+	b.WriteString(`[{"common":`)
+	b.Write(w.common)
+	b.WriteString(`,"logs":[`)
+	for i, r := range batch {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(`{"timestamp":`)
+		appendJSON(&b, r.time.UnixMilli())
+		b.WriteString(`,"message":`)
+		appendJSON(&b, string(r.p))
+		b.WriteByte('}')
+	}
+	b.WriteString(`]}]`)
+
+	var body bytes.Buffer
+	zw := gzip.NewWriter(&body)
+	zw.Write(b.Bytes())
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.opts.Endpoint, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	req.Header.Set("X-License-Key", w.opts.LicenseKey)
+	return req, nil
+}
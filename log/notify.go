@@ -0,0 +1,351 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+)
+
+// PagerDutyEndpoint is the URL of the PagerDuty Events API v2.
+const PagerDutyEndpoint = "https://events.pagerduty.com/v2/enqueue"
+
+// Defaults of NotifierOptions.
+const (
+	DefaultNotifyDelay       = 5 * time.Second
+	DefaultNotifyInterval    = 5 * time.Minute
+	DefaultNotifyDedupWindow = time.Hour
+)
+
+// maxNotifyRecords is the number of distinct records listed in a notification. Further records are
+// only counted.
+const maxNotifyRecords = 20
+
+// NotifierOptions configures a Notifier. At least one of SlackWebhookURL and PagerDutyRoutingKey
+// must be set; notifications are sent to both if both are.
+type NotifierOptions struct {
+	// SlackWebhookURL is the URL of a Slack incoming webhook.
+	SlackWebhookURL string
+	// PagerDutyRoutingKey is the integration key of a PagerDuty service, whose alerts are
+	// triggered through PagerDutyEndpoint.
+	PagerDutyRoutingKey string
+	// Levels are the levels of the records notified. They default to ErrorLevel and FatalLevel.
+	Levels []Level
+	// Delay is how long a notification waits for related records after the first one, so that
+	// a burst of records produces a single notification. It defaults to DefaultNotifyDelay.
+	Delay time.Duration
+	// Interval is the minimum time between notifications. It defaults to DefaultNotifyInterval.
+	Interval time.Duration
+	// DedupWindow is how long a record is not notified again after being notified. Records are
+	// the same if they only differ by numbers, such as timestamps, counters and IDs. It defaults to
+	// DefaultNotifyDedupWindow.
+	DedupWindow time.Duration
+	// Source identifies the program in notifications. It defaults to the executable name and
+	// host name.
+	Source string
+	// Client posts the notifications. It defaults to a client with a timeout of DefaultHTTPTimeout.
+	Client *http.Client
+}
+
+// Notifier alerts people of severe records through Slack or PagerDuty. It is rate limited and
+// deduplicates records, so that a storm of errors produces one alert rather than a thousand:
+// records arriving together are grouped into one notification, notifications are spaced by a
+// minimum interval, and records already notified are not notified again for a while. Notifications
+// are sent from a goroutine of their own, except when the notifier is flushed, as happens for fatal
+// records, so that the alert goes out before the program exits.
+//
+// A Notifier is a LevelWriter, given to loggers like any other destination. Records written to it
+// with Write rather than WriteLevel are considered errors.
+type Notifier struct {
+	opts    NotifierOptions
+	levels  map[Level]bool
+	queue   chan notifyRecord
+	dropped atomic.Int64
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+	err    atomic.Value
+}
+
+// notifyRecord is a record queued by a Notifier, or a flush request if flushed is not nil.
+type notifyRecord struct {
+	level   Level
+	p       []byte
+	flushed chan struct{}
+}
+
+// notifyEntry is a distinct record pending notification, and how many times it was logged.
+type notifyEntry struct {
+	key   string
+	level Level
+	text  string
+	count int
+}
+
+// NewNotifier returns a Notifier configured with opts. It fails if no destination is configured.
+func NewNotifier(opts NotifierOptions) (*Notifier, error) {
+	if opts.SlackWebhookURL == "" && opts.PagerDutyRoutingKey == "" {
+		return nil, errors.New("no Slack webhook or PagerDuty routing key to notify")
+	}
+	if len(opts.Levels) == 0 {
+		opts.Levels = []Level{ErrorLevel, FatalLevel}
+	}
+	if opts.Delay <= 0 {
+		opts.Delay = DefaultNotifyDelay
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultNotifyInterval
+	}
+	if opts.DedupWindow <= 0 {
+		opts.DedupWindow = DefaultNotifyDedupWindow
+	}
+	if opts.Source == "" {
+		host, _ := os.Hostname()
+		opts.Source = executableName() + "@" + host
+	}
+	if opts.Client == nil {
+		opts.Client = defaultHTTPClient
+	}
+
+	n := &Notifier{
+		opts:   opts,
+		levels: map[Level]bool{},
+		queue:  make(chan notifyRecord, 256),
+		done:   make(chan struct{}),
+	}
+	for _, level := range opts.Levels {
+		n.levels[level] = true
+	}
+	go n.run()
+	return n, nil
+}
+
+// Write queues the record p for notification as an error.
+func (n *Notifier) Write(p []byte) (int, error) {
+	return n.WriteLevel(ErrorLevel, p)
+}
+
+// WriteLevel queues the record p for notification if level is one of the notified levels. Records
+// are dropped if the queue is full. It never reports errors of the destinations; see Err.
+func (n *Notifier) WriteLevel(level Level, p []byte) (int, error) {
+	if !n.levels[level] {
+		return len(p), nil
+	}
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	if n.closed {
+		return 0, ErrWriterClosed
+	}
+
+	select {
+	case n.queue <- notifyRecord{level: level, p: bytes.TrimSpace(append([]byte(nil), p...))}:
+	default:
+		n.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// Flush sends a notification of the records queued before the call right away, ignoring the
+// minimum interval between notifications.
+func (n *Notifier) Flush() error {
+	n.mu.RLock()
+	if n.closed {
+		n.mu.RUnlock()
+		return nil
+	}
+	flushed := make(chan struct{})
+	n.queue <- notifyRecord{flushed: flushed}
+	n.mu.RUnlock()
+
+	<-flushed
+	return n.Err()
+}
+
+// Close sends a notification of the queued records and stops the notifier.
+func (n *Notifier) Close() error {
+	n.mu.Lock()
+	if !n.closed {
+		n.closed = true
+		close(n.queue)
+	}
+	n.mu.Unlock()
+
+	<-n.done
+	return n.Err()
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (n *Notifier) Dropped() int64 {
+	return n.dropped.Load()
+}
+
+// Err returns the last error sending a notification, if any.
+func (n *Notifier) Err() error {
+	err, _ := n.err.Load().(error)
+	return err
+}
+
+// run groups queued records into notifications until the queue is closed.
+func (n *Notifier) run() {
+	defer close(n.done)
+	var (
+		pending  []*notifyEntry
+		byKey    = map[string]*notifyEntry{}
+		notified = map[string]time.Time{}
+		overflow int
+		lastSent time.Time
+		timer    *time.Timer
+		timerC   <-chan time.Time
+	)
+	send := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, timerC = nil, nil
+		}
+		if len(pending) == 0 {
+			return
+		}
+		now := time.Now()
+		for key, t := range notified {
+			if now.Sub(t) >= n.opts.DedupWindow {
+				delete(notified, key)
+			}
+		}
+		for _, e := range pending {
+			notified[e.key] = now
+		}
+		if err := n.notify(pending, overflow); err != nil {
+			n.err.Store(err)
+		}
+		pending, byKey, overflow, lastSent = nil, map[string]*notifyEntry{}, 0, now
+	}
+
+	for {
+		select {
+		case req, ok := <-n.queue:
+			switch {
+			case !ok:
+				send()
+				return
+			case req.flushed != nil:
+				send()
+				close(req.flushed)
+				continue
+			}
+
+			now := time.Now()
+			key := notifyKey(req.p)
+			if t, ok := notified[key]; ok && now.Sub(t) < n.opts.DedupWindow {
+				continue
+			}
+			if e := byKey[key]; e != nil {
+				e.count++
+				continue
+			}
+			if len(pending) == maxNotifyRecords {
+				overflow++
+				continue
+			}
+			e := &notifyEntry{key: key, level: req.level, text: string(req.p), count: 1}
+			pending = append(pending, e)
+			byKey[key] = e
+			if timer == nil {
+				at := now.Add(n.opts.Delay)
+				if next := lastSent.Add(n.opts.Interval); next.After(at) {
+					at = next
+				}
+				timer = time.NewTimer(at.Sub(now))
+				timerC = timer.C
+			}
+		case <-timerC:
+			send()
+		}
+	}
+}
+
+// notifyKey returns the key identifying records that are the same but for their numbers.
+func notifyKey(p []byte) string {
+	var b strings.Builder
+	digits := false
+	for _, r := range string(p) {
+		if unicode.IsDigit(r) {
+			if !digits {
+				b.WriteByte('#')
+			}
+			digits = true
+			continue
+		}
+		digits = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// notify sends a notification of entries, and of overflow more distinct records, to the configured
+// destinations.
+func (n *Notifier) notify(entries []*notifyEntry, overflow int) error {
+	var b strings.Builder
+	for _, e := range entries {
+		b.WriteString(e.text)
+		if e.count > 1 {
+			fmt.Fprintf(&b, " (x%d)", e.count)
+		}
+		b.WriteByte('\n')
+	}
+	if overflow > 0 {
+		fmt.Fprintf(&b, "... and %d more distinct records\n", overflow)
+	}
+	details := b.String()
+
+	var errs []error
+	if n.opts.SlackWebhookURL != "" {
+		text := fmt.Sprintf("*%s* logged severe records:\n```\n%s```", n.opts.Source, details)
+		errs = append(errs, n.post(n.opts.SlackWebhookURL, map[string]interface{}{"text": text}))
+	}
+	if n.opts.PagerDutyRoutingKey != "" {
+		severity := "error"
+		for _, e := range entries {
+			if e.level == FatalLevel {
+				severity = "critical"
+			}
+		}
+		summary, _, _ := strings.Cut(entries[0].text, "\n")
+		errs = append(errs, n.post(PagerDutyEndpoint, map[string]interface{}{
+			"routing_key":  n.opts.PagerDutyRoutingKey,
+			"event_action": "trigger",
+			"dedup_key":    n.opts.Source + ":" + fingerprint(entries[0].key, 0, ""),
+			"payload": map[string]interface{}{
+				"summary":        truncate(summary, 1000),
+				"source":         n.opts.Source,
+				"severity":       severity,
+				"custom_details": map[string]interface{}{"records": details},
+			},
+		}))
+	}
+	return errors.Join(errs...)
+}
+
+// post posts the JSON encoding of body to url.
+func (n *Notifier) post(url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp, err := n.opts.Client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("sending notification to %s: %s", resp.Request.URL.Host, resp.Status)
+	}
+	return nil
+}
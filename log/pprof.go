@@ -0,0 +1,34 @@
+package log
+
+// SetPprofLabels implements the Logger interface.
+func (l *logger) SetPprofLabels(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pprofLabels = append([]string(nil), keys...)
+}
+
+// SetPprofLabels is a convenience method that calls defaultLogger.SetPprofLabels(keys...)
+func SetPprofLabels(keys ...string) {
+	defaultLogger.SetPprofLabels(keys...)
+}
+
+// pprofLabelFields returns the pprof labels of the current goroutine selected by l as fields, in
+// the order of l's keys. It must be called with l.mu held.
+func (l *logger) pprofLabelFields() []Field {
+	if len(l.pprofLabels) == 0 {
+		return nil
+	}
+	lookup := goroutinePprofLabels()
+	if lookup == nil {
+		return nil
+	}
+
+	var fields []Field
+	for _, key := range l.pprofLabels {
+		if value, ok := lookup(key); ok {
+			fields = append(fields, Field{Key: key, Value: value})
+		}
+	}
+	return fields
+}
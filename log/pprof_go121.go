@@ -0,0 +1,12 @@
+//go:build !go1.24
+
+package log
+
+import "unsafe"
+
+// pprofLabel returns the value of the label key in the pprof labels of a goroutine, which are a
+// map before Go 1.24.
+func pprofLabel(labels unsafe.Pointer, key string) (string, bool) {
+	value, ok := (*(*map[string]string)(labels))[key]
+	return value, ok
+}
@@ -0,0 +1,20 @@
+//go:build go1.24 && !go1.28
+
+package log
+
+import "unsafe"
+
+// pprofLabelSet is the layout of the pprof labels of a goroutine since Go 1.24.
+type pprofLabelSet struct {
+	list []struct{ key, value string }
+}
+
+// pprofLabel returns the value of the label key in the pprof labels of a goroutine.
+func pprofLabel(labels unsafe.Pointer, key string) (string, bool) {
+	for _, label := range (*pprofLabelSet)(labels).list {
+		if label.key == key {
+			return label.value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,26 @@
+//go:build !go1.28
+
+package log
+
+import "unsafe"
+
+// runtime_getProfLabel returns the pprof labels of the current goroutine, as set by pprof.Do or
+// pprof.SetGoroutineLabels, or nil if it has none. The runtime keeps it available for linkname
+// (go.dev/issue/67401), but their layout is private to the runtime, so it is only read with the
+// Go releases it is known for. Every new release must be checked before raising the upper bound
+// of the build constraints of this file and pprof_go124.go.
+//
+//go:linkname runtime_getProfLabel runtime/pprof.runtime_getProfLabel
+func runtime_getProfLabel() unsafe.Pointer
+
+// goroutinePprofLabels returns a function looking up the pprof labels of the current goroutine,
+// or nil if it has none.
+func goroutinePprofLabels() func(key string) (string, bool) {
+	labels := runtime_getProfLabel()
+	if labels == nil {
+		return nil
+	}
+	return func(key string) (string, bool) {
+		return pprofLabel(labels, key)
+	}
+}
@@ -0,0 +1,58 @@
+package log
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// DevelopmentVerbosity is the verbosity of the development preset, which shows debugging output
+// logged at verbosity 1.
+const DevelopmentVerbosity = 1
+
+// ProductionQuotas are the quotas of the production preset, which cap info records at 1000 per
+// second during bursts, dropping the rest, while keeping every warning and error. A "records over
+// quota were suppressed" warning reports the number of records dropped in each second.
+var ProductionQuotas = []Quota{{Level: InfoLevel, Max: 1000, Window: time.Second}}
+
+// DevelopmentOptions returns the options of the development preset, for Init: colorful,
+// timestamped text records with continuation lines marked on stderr only, debugging output at
+// DevelopmentVerbosity, and failed assertions and DPanic stopping the program.
+func DevelopmentOptions() *LogOptions {
+	return &LogOptions{
+		Verbosity:        DevelopmentVerbosity,
+		Colorful:         true,
+		Timestamp:        true,
+		StderrOnly:       true,
+		MultilineMarkers: true,
+		FatalStacks:      true,
+		Development:      true,
+	}
+}
+
+// ProductionOptions returns the options of the production preset, for Init: timestamped records
+// in a log file in FormatOTel as well as text on stderr, info records capped with
+// ProductionQuotas, control characters escaped, and failed assertions and DPanic logged as errors
+// without stopping the program.
+func ProductionOptions() *LogOptions {
+	return &LogOptions{
+		Timestamp:  true,
+		Format:     FormatOTel,
+		Production: true,
+		Sanitize:   SanitizeEscape,
+		Quotas:     append([]Quota(nil), ProductionQuotas...),
+	}
+}
+
+// NewDevelopment returns a logger configured by DevelopmentOptions, writing to stderr.
+func NewDevelopment() Logger {
+	return newConfiguredLogger(DevelopmentOptions(), nil)
+}
+
+// NewProduction returns a logger configured by ProductionOptions, writing its records to stderr
+// in FormatOTel only.
+func NewProduction() Logger {
+	l := newConfiguredLogger(ProductionOptions(), []io.Writer{os.Stderr})
+	l.logToStderr = false
+	return l
+}
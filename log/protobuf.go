@@ -0,0 +1,97 @@
+package log
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Protocol buffer wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+// encodeProtobuf returns r as a length-delimited LogRecord message, with the cleaned msg and
+// fields. See logrecord.proto for the schema.
+func (l *logger) encodeProtobuf(r *Record, msg string, fields []Field) []byte {
+	var m []byte
+	m = appendVarintField(m, 1, SchemaVersion)
+	m = appendVarintField(m, 2, uint64(r.Time.UnixNano()))
+	m = appendVarintField(m, 3, uint64(r.Level))
+	if r.Level != FatalLevel {
+		m = appendVarintField(m, 4, uint64(r.Seq))
+	}
+	m = appendStringField(m, 5, r.File)
+	m = appendVarintField(m, 6, uint64(r.Line))
+	m = appendStringField(m, 7, msg)
+	for _, f := range fields {
+		m = appendBytes(m, 8, appendProtoField(nil, f))
+	}
+	m = appendStringField(m, 9, l.serviceName)
+	m = appendStringField(m, 10, string(r.Stacks))
+
+	b := binary.AppendUvarint(make([]byte, 0, len(m)+binary.MaxVarintLen32), uint64(len(m)))
+	return append(b, m...)
+}
+
+// appendProtoField appends f to b as a Field message, choosing the value field from the type of
+// f's value. Values other than numbers and booleans are encoded as their text.
+func appendProtoField(b []byte, f Field) []byte {
+	b = appendStringField(b, 1, f.Key)
+	switch v := f.Value.(type) {
+	case error, fmt.Stringer:
+		// Encode durations and the like as the text users would expect rather than their kind.
+		return appendBytes(b, 2, []byte(fmt.Sprint(v)))
+	}
+
+	rv := reflect.ValueOf(f.Value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendVarint(b, 3, uint64(rv.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendVarint(b, 4, rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		b = binary.AppendUvarint(b, 5<<3|wireFixed64)
+		return binary.LittleEndian.AppendUint64(b, math.Float64bits(rv.Float()))
+	case reflect.Bool:
+		var n uint64
+		if rv.Bool() {
+			n = 1
+		}
+		return appendVarint(b, 6, n)
+	}
+	return appendBytes(b, 2, []byte(fmt.Sprint(f.Value)))
+}
+
+// appendVarint appends field num with the varint v to b.
+func appendVarint(b []byte, num int, v uint64) []byte {
+	b = binary.AppendUvarint(b, uint64(num)<<3|wireVarint)
+	return binary.AppendUvarint(b, v)
+}
+
+// appendBytes appends field num with the length-delimited data to b.
+func appendBytes(b []byte, num int, data []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(num)<<3|wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+// appendVarintField is like appendVarint, but leaves out zero values as proto3 does.
+func appendVarintField(b []byte, num int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	return appendVarint(b, num, v)
+}
+
+// appendStringField appends field num with the string s to b, leaving out empty strings as proto3
+// does.
+func appendStringField(b []byte, num int, s string) []byte {
+	if s == "" {
+		return b
+	}
+	return appendBytes(b, num, []byte(s))
+}
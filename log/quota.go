@@ -0,0 +1,91 @@
+package log
+
+import "time"
+
+// Quota caps the number of records of a level per time window. For example, a quota of 10000 info
+// records per minute is Quota{Level: InfoLevel, Max: 10000, Window: time.Minute}.
+type Quota struct {
+	Level  Level
+	Max    int
+	Window time.Duration
+}
+
+// quotaWindow counts the records of a level admitted and dropped since the window started.
+type quotaWindow struct {
+	start      time.Time
+	count      int
+	suppressed int64
+}
+
+// SetQuotas implements the Logger interface.
+func (l *logger) SetQuotas(quotas ...Quota) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.quotas = append([]Quota(nil), quotas...)
+	l.quotaWindows = nil
+}
+
+// SetQuotas is a convenience method that calls defaultLogger.SetQuotas(quotas...)
+func SetQuotas(quotas ...Quota) {
+	defaultLogger.SetQuotas(quotas...)
+}
+
+// admit reports whether a record of level logged at file and line is within its level's quota,
+// counting it if so. Suppression notices and summaries are written at the same location, so that
+// they point at the runaway statement that started the suppression. It must be called with l.mu
+// held.
+func (l *logger) admit(level Level, file string, line int, callerOK bool) bool {
+	if level == FatalLevel || level == AuditLevel {
+		return true
+	}
+	q, ok := l.quota(level)
+	if !ok {
+		return true
+	}
+
+	now := time.Now()
+	w := l.quotaWindows[level]
+	if w == nil || now.Sub(w.start) >= q.Window {
+		if w != nil && w.suppressed > 0 {
+			l.emit(WarningLevel, 0, "records over quota were suppressed", []Field{
+				{Key: "level", Value: level},
+				{Key: "suppressed", Value: w.suppressed},
+				{Key: "max", Value: q.Max},
+				{Key: "window", Value: q.Window},
+			}, file, line, callerOK)
+		}
+		w = &quotaWindow{start: now}
+		if l.quotaWindows == nil {
+			l.quotaWindows = map[Level]*quotaWindow{}
+		}
+		l.quotaWindows[level] = w
+	}
+
+	if w.count < q.Max {
+		w.count++
+		return true
+	}
+	if w.suppressed == 0 {
+		l.emit(WarningLevel, 0, "records over quota will be suppressed", []Field{
+			{Key: "level", Value: level},
+			{Key: "max", Value: q.Max},
+			{Key: "window", Value: q.Window},
+			{Key: "until", Value: w.start.Add(q.Window).Format(time.RFC3339Nano)},
+		}, file, line, callerOK)
+	}
+	w.suppressed++
+	reportDropped("quota", 1)
+	return false
+}
+
+// quota returns the quota of level, if any. If several quotas are set for a level, the last one
+// applies.
+func (l *logger) quota(level Level) (Quota, bool) {
+	for i := len(l.quotas) - 1; i >= 0; i-- {
+		if q := l.quotas[i]; q.Level == level && q.Window > 0 {
+			return q, true
+		}
+	}
+	return Quota{}, false
+}
@@ -0,0 +1,312 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is a log record. Loggers build one for every logging call and hand it to their encoders;
+// WriteRecord accepts records produced elsewhere, such as by another process or logging library.
+//
+// Records marshal to JSON objects such as
+//
+//	{"time":"2024-05-01T12:00:00Z","level":"WARNING","verbosity":0,"seq":3,"file":"main.go",
+//	"line":12,"message":"disk full","fields":{"free":0}}
+//
+// and to text lines such as
+//
+//	time=2024-05-01T12:00:00Z level=WARNING verbosity=0 seq=3 file=main.go line=12 msg="disk full" free=0
+//
+// in which the fields follow the message. Field values don't keep their Go types through a round
+// trip: they come back from JSON as strings, bools, json.Number values, slices and maps, and from
+// text as strings.
+type Record struct {
+	// Time is the time of the event. A zero Time given to WriteRecord means the time the record is
+	// written.
+	Time time.Time
+
+	Level     Level
+	Verbosity int
+
+	// Seq is the number of records of the level the logger wrote before this one. WriteRecord
+	// ignores it.
+	Seq int64
+
+	// File and Line identify the source of the record. An empty File given to WriteRecord means it
+	// is unknown.
+	File string
+	Line int
+
+	Message string
+	Fields  []Field
+
+	// Stacks holds the stacks of all goroutines for fatal records of loggers configured with
+	// SetFatalStacks, or nil.
+	Stacks []byte
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (level Level) MarshalText() ([]byte, error) {
+	s := level.String()
+	if strings.HasPrefix(s, "Level(") {
+		return nil, fmt.Errorf("log: invalid level %d", int(level))
+	}
+	return []byte(s), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. Level names are matched
+// regardless of case.
+func (level *Level) UnmarshalText(text []byte) error {
+	for l := InfoLevel; l <= AuditLevel; l++ {
+		if strings.EqualFold(string(text), l.String()) {
+			*level = l
+			return nil
+		}
+	}
+	return fmt.Errorf("log: invalid level %q", text)
+}
+
+// jsonRecord is the JSON form of a Record, except for its fields, which are decoded separately to
+// keep their order.
+type jsonRecord struct {
+	Time      time.Time       `json:"time"`
+	Level     Level           `json:"level"`
+	Verbosity int             `json:"verbosity"`
+	Seq       int64           `json:"seq"`
+	File      string          `json:"file"`
+	Line      int             `json:"line"`
+	Message   string          `json:"message"`
+	Fields    json.RawMessage `json:"fields,omitempty"`
+	Stacks    *string         `json:"stacks,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r Record) MarshalJSON() ([]byte, error) {
+	level, err := r.Level.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteString(`{"time":`)
+	appendJSON(&b, r.Time.Format(time.RFC3339Nano))
+	b.WriteString(`,"level":`)
+	appendJSON(&b, string(level))
+	fmt.Fprintf(&b, `,"verbosity":%d,"seq":%d,"file":`, r.Verbosity, r.Seq)
+	appendJSON(&b, r.File)
+	fmt.Fprintf(&b, `,"line":%d,"message":`, r.Line)
+	appendJSON(&b, r.Message)
+	if len(r.Fields) > 0 {
+		b.WriteString(`,"fields":{`)
+		for i, f := range r.Fields {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			appendJSON(&b, f.Key)
+			b.WriteByte(':')
+			appendJSON(&b, jsonValue(f.Value))
+		}
+		b.WriteByte('}')
+	}
+	if r.Stacks != nil {
+		b.WriteString(`,"stacks":`)
+		appendJSON(&b, string(r.Stacks))
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *Record) UnmarshalJSON(data []byte) error {
+	var j jsonRecord
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	fields, err := unmarshalFields(j.Fields)
+	if err != nil {
+		return err
+	}
+
+	*r = Record{Time: j.Time, Level: j.Level, Verbosity: j.Verbosity, Seq: j.Seq, File: j.File,
+		Line: j.Line, Message: j.Message, Fields: fields}
+	if j.Stacks != nil {
+		r.Stacks = []byte(*j.Stacks)
+	}
+	return nil
+}
+
+// unmarshalFields decodes the JSON object data into fields, in the order of its keys.
+func unmarshalFields(data json.RawMessage) ([]Field, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if t, err := dec.Token(); err != nil {
+		return nil, err
+	} else if t != json.Delim('{') {
+		return nil, errors.New("log: record fields are not an object")
+	}
+
+	var fields []Field
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		fields = append(fields, Field{Key: t.(string), Value: v})
+	}
+	return fields, nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (r Record) MarshalText() ([]byte, error) {
+	level, err := r.Level.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	appendTextPair(&b, "time", r.Time.Format(time.RFC3339Nano))
+	appendTextPair(&b, "level", string(level))
+	appendTextPair(&b, "verbosity", strconv.Itoa(r.Verbosity))
+	appendTextPair(&b, "seq", strconv.FormatInt(r.Seq, 10))
+	appendTextPair(&b, "file", r.File)
+	appendTextPair(&b, "line", strconv.Itoa(r.Line))
+	if r.Stacks != nil {
+		appendTextPair(&b, "stacks", string(r.Stacks))
+	}
+	appendTextPair(&b, "msg", r.Message)
+	for _, f := range r.Fields {
+		if f.Key == "" || strings.ContainsAny(f.Key, " \t\r\n\"=") {
+			return nil, fmt.Errorf("log: field key %q can't be written as text", f.Key)
+		}
+		appendTextPair(&b, f.Key, fmt.Sprint(f.Value))
+	}
+	return []byte(b.String()), nil
+}
+
+// appendTextPair writes key=value to b, after a space unless b is empty, quoting the value if it
+// would otherwise be ambiguous.
+func appendTextPair(b *strings.Builder, key, value string) {
+	if b.Len() > 0 {
+		b.WriteByte(' ')
+	}
+	b.WriteString(key)
+	b.WriteByte('=')
+	if value == "" || strings.ContainsAny(value, " \t\r\n\"=") || !strconv.CanBackquote(value) {
+		value = strconv.Quote(value)
+	}
+	b.WriteString(value)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It accepts the form written by
+// MarshalText, in which the keys before msg describe the record and those after it are its fields.
+func (r *Record) UnmarshalText(text []byte) error {
+	rec := Record{}
+	s := strings.TrimRight(string(text), "\r\n")
+	inFields := false
+	for s != "" {
+		key, value, rest, err := nextTextPair(s)
+		if err != nil {
+			return err
+		}
+		s = rest
+		if inFields {
+			rec.Fields = append(rec.Fields, Field{Key: key, Value: value})
+			continue
+		}
+		switch key {
+		case "time":
+			rec.Time, err = time.Parse(time.RFC3339Nano, value)
+		case "level":
+			err = rec.Level.UnmarshalText([]byte(value))
+		case "verbosity":
+			rec.Verbosity, err = strconv.Atoi(value)
+		case "seq":
+			rec.Seq, err = strconv.ParseInt(value, 10, 64)
+		case "file":
+			rec.File = value
+		case "line":
+			rec.Line, err = strconv.Atoi(value)
+		case "stacks":
+			rec.Stacks = []byte(value)
+		case "msg":
+			rec.Message, inFields = value, true
+		default:
+			err = fmt.Errorf("log: unknown record key %q before msg", key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if !inFields {
+		return errors.New("log: record text has no msg")
+	}
+	*r = rec
+	return nil
+}
+
+// nextTextPair splits the key=value pair at the start of s from the rest of s, unquoting the
+// value if it is quoted.
+func nextTextPair(s string) (key, value, rest string, err error) {
+	s = strings.TrimLeft(s, " ")
+	key, s, ok := strings.Cut(s, "=")
+	if !ok || key == "" || strings.ContainsAny(key, " \t") {
+		return "", "", "", fmt.Errorf("log: invalid record text near %q", key)
+	}
+	if strings.HasPrefix(s, `"`) {
+		quoted, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return "", "", "", fmt.Errorf("log: invalid value of %s: %w", key, err)
+		}
+		value, _ = strconv.Unquote(quoted)
+		return key, value, s[len(quoted):], nil
+	}
+	value, rest, _ = strings.Cut(s, " ")
+	return key, value, rest, nil
+}
+
+// WriteRecord implements the Logger interface.
+func (l *logger) WriteRecord(r Record) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if r.Verbosity > l.inheritedVerbosity() {
+		return
+	}
+	callerOK := r.File != ""
+	if !l.admit(r.Level, r.File, r.Line, callerOK) {
+		return
+	}
+	if !callerOK {
+		r.File, r.Line = "unknown file", 0
+	}
+	if r.Time.IsZero() {
+		r.Time = l.eventTime
+	}
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	if callerOK {
+		r.Fields = l.fingerprintFields(r.Fields, r.File, r.Line, r.Message)
+	}
+	r.Verbosity = max(r.Verbosity, 0)
+	r.Seq = l.count[r.Level]
+	r.Fields = l.recordFields(r.Fields)
+	l.output(&r)
+}
+
+// WriteRecord is a convenience method that calls defaultLogger.WriteRecord(r)
+func WriteRecord(r Record) {
+	defaultLogger.WriteRecord(r)
+}
@@ -0,0 +1,98 @@
+package log
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RedactedValue replaces the values of sensitive keys in log messages.
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactKeys is a reasonable set of sensitive key names to pass to SetRedactKeys.
+var DefaultRedactKeys = []string{"password", "passwd", "secret", "token", "authorization", "api_key", "ssn"}
+
+// newRedactor returns a regular expression matching key/value pairs for any of keys in messages,
+// or nil if there are no keys. The first submatch is everything before the value. Keys match
+// case-insensitively and may be quoted, and values may be separated from their key by '=' or ':'.
+// Authorization schemes such as "Bearer" are considered part of the value.
+func newRedactor(keys []string) *regexp.Regexp {
+	var quoted []string
+	for _, key := range keys {
+		if key != "" {
+			quoted = append(quoted, regexp.QuoteMeta(key))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+
+	return regexp.MustCompile(`(?i)(["']?\b(?:` + strings.Join(quoted, "|") + `)\b["']?\s*[:=]\s*)` +
+		`(?:(?:basic|bearer|digest|token)\s+)?("[^"]*"|'[^']*'|[^\s,;&"']+)`)
+}
+
+// redact replaces the values of all key/value pairs matched by re in s with RedactedValue.
+func redact(re *regexp.Regexp, s string) string {
+	if re == nil {
+		return s
+	}
+	return re.ReplaceAllString(s, "${1}"+RedactedValue)
+}
+
+// Scrubber removes sensitive information from log messages. Scrubbers run on every message a logger
+// writes, after key redaction and scrub rules, which lets organizations plug in their own PII
+// detection.
+type Scrubber interface {
+	// Scrub returns msg with any sensitive information removed. It may be called concurrently.
+	Scrub(msg string) string
+}
+
+// ScrubberFunc adapts an ordinary function to the Scrubber interface.
+type ScrubberFunc func(msg string) string
+
+// Scrub implements the Scrubber interface.
+func (f ScrubberFunc) Scrub(msg string) string {
+	return f(msg)
+}
+
+// ScrubRule replaces every match of Pattern in a message with Replacement, which may refer to
+// submatches as described by regexp.Regexp.Expand, for example "$1****".
+type ScrubRule struct {
+	Pattern     *regexp.Regexp
+	Replacement string
+}
+
+// Common scrub rules for secrets that are often interpolated into messages by accident.
+var (
+	// ScrubCreditCards masks 13 to 19 digit card numbers, optionally grouped by spaces or dashes,
+	// keeping the last four digits.
+	ScrubCreditCards = ScrubRule{
+		Pattern:     regexp.MustCompile(`\b(?:\d[ -]?){9,15}(\d{4})\b`),
+		Replacement: "****${1}",
+	}
+	// ScrubBearerTokens masks bearer tokens.
+	ScrubBearerTokens = ScrubRule{
+		Pattern:     regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9\-._~+/]+=*`),
+		Replacement: "${1}" + RedactedValue,
+	}
+	// ScrubEmails masks the local part of email addresses.
+	ScrubEmails = ScrubRule{
+		Pattern:     regexp.MustCompile(`\b[A-Za-z0-9._%+\-]+@([A-Za-z0-9.\-]+\.[A-Za-z]{2,})\b`),
+		Replacement: "****@${1}",
+	}
+)
+
+// Scrub implements the Scrubber interface.
+func (r ScrubRule) Scrub(msg string) string {
+	return r.Pattern.ReplaceAllString(msg, r.Replacement)
+}
+
+// scrub applies rules and then scrubbers to s in order.
+func scrub(rules []ScrubRule, scrubbers []Scrubber, s string) string {
+	for _, rule := range rules {
+		s = rule.Scrub(s)
+	}
+	for _, scrubber := range scrubbers {
+		s = scrubber.Scrub(s)
+	}
+	return s
+}
@@ -0,0 +1,51 @@
+package log
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// scopeIDs hands out the IDs that tie the begin and end records of a Scope together.
+var scopeIDs atomic.Int64
+
+// Scope is a long-running operation started by Logger.Begin. Its begin and end records share a
+// "scope" field so that they can be paired up in the log stream.
+type Scope struct {
+	l      *logger
+	name   string
+	fields []Field
+	start  time.Time
+}
+
+// scopeSkip is the number of stack frames between logwDepth and the caller of Scope.End.
+const scopeSkip = 2
+
+// Begin implements the Logger interface.
+func (l *logger) Begin(name string, fields ...Field) *Scope {
+	s := &Scope{
+		l:      l,
+		name:   name,
+		fields: append([]Field{{Key: "scope", Value: scopeIDs.Add(1)}}, fields...),
+		start:  time.Now(),
+	}
+	// Skip logwDepth and Begin, plus the package-level function for the default logger.
+	l.logwDepth(l.callerSkip-1, l.defaultVerbosity, InfoLevel, "begin "+name, s.fields)
+	return s
+}
+
+// End logs the completion of the operation with its duration. If err is not nil, the operation is
+// considered failed and the record is logged as an error including err.
+func (s *Scope) End(err error) {
+	fields := append(s.fields[:len(s.fields):len(s.fields)], Field{Key: "duration", Value: time.Since(s.start)})
+	level := InfoLevel
+	if err != nil {
+		level = ErrorLevel
+		fields = append(fields, Field{Key: "error", Value: err})
+	}
+	s.l.logwDepth(scopeSkip, s.l.defaultVerbosity, level, "end "+s.name, fields)
+}
+
+// Begin is a convenience method that calls defaultLogger.Begin(name, fields...)
+func Begin(name string, fields ...Field) *Scope {
+	return defaultLogger.Begin(name, fields...)
+}
@@ -0,0 +1,49 @@
+package log
+
+// Severity is the severity of a record in the vocabularies of external systems.
+type Severity struct {
+	// Syslog is the RFC 5424 severity, from 0 (emergency) to 7 (debug).
+	Syslog int
+	// OTel is the OpenTelemetry severity number, from 1 (TRACE) to 24 (FATAL4).
+	OTel int
+	// GCP is the Google Cloud Logging severity, such as "INFO" or "CRITICAL".
+	GCP string
+	// CEF is the Common Event Format severity, from 0 to 10.
+	CEF int
+}
+
+// SeverityFunc maps the level of a record, and the verbosity it was logged at, to its severity. The
+// verbosity is zero for records that aren't verbose.
+type SeverityFunc func(level Level, verbosity int) Severity
+
+// DefaultSeverities are the severities of records of each level used by DefaultSeverity.
+var DefaultSeverities = map[Level]Severity{
+	InfoLevel:    {Syslog: 6, OTel: 9, GCP: "INFO", CEF: 3},
+	WarningLevel: {Syslog: 4, OTel: 13, GCP: "WARNING", CEF: 5},
+	ErrorLevel:   {Syslog: 3, OTel: 17, GCP: "ERROR", CEF: 7},
+	FatalLevel:   {Syslog: 2, OTel: 21, GCP: "CRITICAL", CEF: 10},
+	AuditLevel:   {Syslog: 5, OTel: 9, GCP: "NOTICE", CEF: 3},
+}
+
+// VerboseSeverity is the severity DefaultSeverity uses for info records logged at a verbosity above
+// zero, which are debugging output.
+var VerboseSeverity = Severity{Syslog: 7, OTel: 5, GCP: "DEBUG", CEF: 1}
+
+// DefaultSeverity is the default SeverityFunc. It maps verbose info records to VerboseSeverity and
+// other records to DefaultSeverities. Both may be modified at init time to adapt the mapping
+// without writing a SeverityFunc.
+func DefaultSeverity(level Level, verbosity int) Severity {
+	if level == InfoLevel && verbosity > 0 {
+		return VerboseSeverity
+	}
+	return DefaultSeverities[level]
+}
+
+// severityOf returns the severity of r according to the logger's mapping. It must be called with
+// l.mu held.
+func (l *logger) severityOf(r *Record) Severity {
+	if l.severity == nil {
+		return DefaultSeverity(r.Level, r.Verbosity)
+	}
+	return l.severity(r.Level, r.Verbosity)
+}
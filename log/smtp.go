@@ -0,0 +1,188 @@
+package log
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultSMTPRecent is the default number of recent records included in emails of
+	// SMTPNotifier.
+	DefaultSMTPRecent = 50
+
+	// DefaultSMTPTimeout is the default time SMTPNotifier allows for sending an email.
+	DefaultSMTPTimeout = 10 * time.Second
+)
+
+// SMTPOptions configures an SMTPNotifier.
+type SMTPOptions struct {
+	// Addr is the address of the mail server, as "host:port".
+	Addr string
+	// Username and Password authenticate to the server with PLAIN authentication, which is only
+	// used over TLS or to localhost. No authentication is attempted if Username is empty.
+	Username string
+	Password string
+	// From is the sender of the emails, and To their recipients.
+	From string
+	To   []string
+	// Recent is the number of records preceding the fatal record included in emails. It defaults
+	// to DefaultSMTPRecent; a negative number includes none.
+	Recent int
+	// Timeout bounds the time taken to connect to the server and send an email, so that an
+	// unresponsive server can't hang the program on its way down. It defaults to
+	// DefaultSMTPTimeout.
+	Timeout time.Duration
+}
+
+// SMTPNotifier emails fatal records to a list of addresses, along with the records logged just
+// before them, for small deployments without paging infrastructure. Emails are sent when the
+// fatal record is written, before the program exits; the logger's fatal timeout should leave
+// enough time for this.
+//
+// An SMTPNotifier is a LevelWriter, given to loggers like any other destination. It receives all
+// records to keep the recent ones, and only sends emails for fatal records.
+type SMTPNotifier struct {
+	opts SMTPOptions
+
+	mu     sync.Mutex
+	recent [][]byte
+	next   int
+	err    error
+}
+
+// NewSMTPNotifier returns an SMTPNotifier configured with opts. It fails if the server, sender or
+// recipients are missing.
+func NewSMTPNotifier(opts SMTPOptions) (*SMTPNotifier, error) {
+	if opts.Addr == "" || opts.From == "" || len(opts.To) == 0 {
+		return nil, errors.New("SMTP notifier needs a server, a sender and recipients")
+	}
+	if opts.Recent == 0 {
+		opts.Recent = DefaultSMTPRecent
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultSMTPTimeout
+	}
+	return &SMTPNotifier{opts: opts}, nil
+}
+
+// Write keeps the record p as a recent record.
+func (n *SMTPNotifier) Write(p []byte) (int, error) {
+	return n.WriteLevel(InfoLevel, p)
+}
+
+// WriteLevel emails the record p if level is FatalLevel, and keeps it as a recent record
+// otherwise.
+func (n *SMTPNotifier) WriteLevel(level Level, p []byte) (int, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if level != FatalLevel {
+		if n.opts.Recent > 0 {
+			if len(n.recent) < n.opts.Recent {
+				n.recent = append(n.recent, nil)
+			}
+			n.recent[n.next] = append(n.recent[n.next][:0], p...)
+			n.next = (n.next + 1) % n.opts.Recent
+		}
+		return len(p), nil
+	}
+
+	if err := n.send(p); err != nil {
+		n.err = err
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Err returns the last error sending an email, if any.
+func (n *SMTPNotifier) Err() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.err
+}
+
+// send emails the fatal record p with the recent records. It must be called with n.mu held.
+func (n *SMTPNotifier) send(p []byte) error {
+	host, _ := os.Hostname()
+	summary, _, _ := strings.Cut(string(bytes.TrimSpace(p)), "\n")
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.opts.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.opts.To, ", "))
+	fmt.Fprintf(&msg, "Subject: [%s@%s] %s\r\n", executableName(), host,
+		strings.NewReplacer("\r", " ", "\n", " ").Replace(truncate(summary, 200)))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+
+	msg.Write(p)
+	if len(n.recent) > 0 {
+		msg.WriteString("\nrecent records:\n")
+		for i := range n.recent {
+			msg.Write(n.recent[(n.next+i)%len(n.recent)])
+		}
+	}
+
+	return n.sendMail(msg.Bytes())
+}
+
+// sendMail sends msg like smtp.SendMail, but within the notifier's timeout.
+func (n *SMTPNotifier) sendMail(msg []byte) error {
+	host, _, err := net.SplitHostPort(n.opts.Addr)
+	if err != nil {
+		return err
+	}
+	conn, err := (&net.Dialer{Timeout: n.opts.Timeout}).Dial("tcp", n.opts.Addr)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(n.opts.Timeout)); err != nil {
+		conn.Close()
+		return err
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if n.opts.Username != "" {
+		auth := smtp.PlainAuth("", n.opts.Username, n.opts.Password, host)
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(n.opts.From); err != nil {
+		return err
+	}
+	for _, to := range n.opts.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,128 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"os"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// consoleStderr is the file the logger writes stderr records to. It is the original stderr while
+// stderr is hijacked.
+var consoleStderr atomic.Pointer[os.File]
+
+func init() {
+	consoleStderr.Store(os.Stderr)
+}
+
+// stderrDrainTimeout is how long RestoreStderr waits for output still in the pipe to be logged.
+const stderrDrainTimeout = time.Second
+
+// stderrHijack is the state of a hijacked stderr.
+type stderrHijack struct {
+	orig *os.File
+	// read end of the pipe.
+	r    *os.File
+	done chan struct{}
+}
+
+var (
+	hijackMu sync.Mutex
+	hijacked *stderrHijack
+)
+
+// HijackStderr redirects the stderr file descriptor of the process to a pipe read by the logger, so
+// that output written to stderr by cgo libraries, dependencies writing to os.Stderr and the like is
+// logged instead of interleaving with the logger's console output. Each line is logged through the
+// default logger as a warning record with a "stream" field of "stderr". The logger itself keeps
+// writing to the original stderr, as does the Go runtime when the program crashes.
+//
+// Writers writing to os.Stderr must not be given to loggers while stderr is hijacked, since their
+// records would be logged again endlessly. HijackStderr does nothing if stderr is already hijacked.
+// It isn't supported on Windows.
+func HijackStderr() error {
+	hijackMu.Lock()
+	defer hijackMu.Unlock()
+	if hijacked != nil {
+		return nil
+	}
+
+	origFd, err := dupFd(int(os.Stderr.Fd()))
+	if err != nil {
+		return err
+	}
+	orig := os.NewFile(uintptr(origFd), os.Stderr.Name())
+	r, w, err := os.Pipe()
+	if err != nil {
+		orig.Close()
+		return err
+	}
+	if err := redirectFd(int(w.Fd()), int(os.Stderr.Fd())); err != nil {
+		orig.Close()
+		r.Close()
+		w.Close()
+		return err
+	}
+	// The stderr descriptor now holds the write end of the pipe open on its own.
+	w.Close()
+
+	consoleStderr.Store(orig)
+	debug.SetCrashOutput(orig, debug.CrashOptions{})
+	h := &stderrHijack{orig: orig, r: r, done: make(chan struct{})}
+	go h.read()
+	hijacked = h
+	return nil
+}
+
+// RestoreStderr undoes HijackStderr, once all output written to stderr so far has been logged. It
+// does nothing if stderr isn't hijacked.
+//
+// Child processes started while stderr is hijacked inherit the pipe as their stderr, and keep it
+// open for as long as they run. RestoreStderr waits up to a second for the output written to the
+// pipe to be logged, then closes it regardless, so output written by such children afterwards is
+// lost, and they may get SIGPIPE. Give them a stderr of their own, such as a LineWriter, instead.
+func RestoreStderr() error {
+	hijackMu.Lock()
+	defer hijackMu.Unlock()
+	if hijacked == nil {
+		return nil
+	}
+
+	// Restoring the descriptor closes the last write end of the pipe, ending the reader.
+	err := redirectFd(int(hijacked.orig.Fd()), int(os.Stderr.Fd()))
+	if err != nil {
+		return err
+	}
+	timer := time.NewTimer(stderrDrainTimeout)
+	defer timer.Stop()
+	select {
+	case <-hijacked.done:
+	case <-timer.C:
+		// A child process holds the write end open, so stop reading.
+		hijacked.r.Close()
+		<-hijacked.done
+	}
+	consoleStderr.Store(os.Stderr)
+	debug.SetCrashOutput(nil, debug.CrashOptions{})
+	err = hijacked.orig.Close()
+	hijacked = nil
+	return err
+}
+
+// read logs the lines read from the pipe until it is closed.
+func (h *stderrHijack) read() {
+	defer close(h.done)
+	defer h.r.Close()
+
+	fields := []Field{{Key: "stream", Value: "stderr"}}
+	w := &LineWriter{logLine: func(s string) {
+		defaultLogger.siteLogger(WarningLevel, fields, "stderr", 0, true)(s)
+	}}
+	if _, err := io.Copy(w, h.r); err != nil && !errors.Is(err, os.ErrClosed) {
+		defaultLogger.Warningf("unable to read hijacked stderr: %v", err)
+	}
+	w.Flush()
+}
@@ -0,0 +1,13 @@
+package log
+
+import "syscall"
+
+// dupFd returns a new descriptor referring to the same file as fd.
+func dupFd(fd int) (int, error) {
+	return syscall.Dup(fd)
+}
+
+// redirectFd makes the descriptor to refer to the same file as the descriptor from.
+func redirectFd(from, to int) error {
+	return syscall.Dup3(from, to, 0)
+}
@@ -0,0 +1,20 @@
+//go:build !unix
+
+package log
+
+import "errors"
+
+// errNoHijack is returned when hijacking stderr isn't supported on this platform.
+var errNoHijack = errors.New("hijacking stderr is not supported on this platform")
+
+// dupFd returns a new descriptor referring to the same file as fd, which isn't supported on this
+// platform.
+func dupFd(fd int) (int, error) {
+	return 0, errNoHijack
+}
+
+// redirectFd makes the descriptor to refer to the same file as the descriptor from, which isn't
+// supported on this platform.
+func redirectFd(from, to int) error {
+	return errNoHijack
+}
@@ -0,0 +1,15 @@
+//go:build unix && !linux
+
+package log
+
+import "syscall"
+
+// dupFd returns a new descriptor referring to the same file as fd.
+func dupFd(fd int) (int, error) {
+	return syscall.Dup(fd)
+}
+
+// redirectFd makes the descriptor to refer to the same file as the descriptor from.
+func redirectFd(from, to int) error {
+	return syscall.Dup2(from, to)
+}
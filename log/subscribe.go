@@ -0,0 +1,80 @@
+package log
+
+import "sync"
+
+// SubscriptionBuffer is the number of records a subscription holds for its consumer. Records
+// arriving while the buffer is full are dropped rather than slowing down logging.
+const SubscriptionBuffer = 1024
+
+// RecordFilter selects the records delivered to a subscription. It is called with the logger
+// locked, so it must be quick and must not log.
+type RecordFilter func(r Record) bool
+
+// subscriptions holds the live subscriptions to the records of a logger and of the loggers derived
+// from it, which share it.
+type subscriptions struct {
+	mu   sync.Mutex
+	subs map[*subscription]struct{}
+}
+
+// subscription is a consumer of records added by Subscribe.
+type subscription struct {
+	filter RecordFilter
+	ch     chan Record
+}
+
+// Subscribe implements the Logger interface.
+func (l *logger) Subscribe(filter RecordFilter) (<-chan Record, func()) {
+	l.mu.Lock()
+	s := l.subscriptions
+	l.mu.Unlock()
+
+	sub := &subscription{filter: filter, ch: make(chan Record, SubscriptionBuffer)}
+	s.mu.Lock()
+	if s.subs == nil {
+		s.subs = map[*subscription]struct{}{}
+	}
+	s.subs[sub] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subs, sub)
+			close(sub.ch)
+			s.mu.Unlock()
+		})
+	}
+	return sub.ch, cancel
+}
+
+// Subscribe is a convenience method that calls defaultLogger.Subscribe(filter)
+func Subscribe(filter RecordFilter) (<-chan Record, func()) {
+	return defaultLogger.Subscribe(filter)
+}
+
+// publish delivers r to the subscriptions of the logger, with its message and fields cleaned as
+// for the structured formats. It must be called with l.mu held.
+func (l *logger) publish(r *Record) {
+	s := l.subscriptions
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.subs) == 0 {
+		return
+	}
+	c := *r
+	c.Message = truncate(scrub(l.scrubRules, l.scrubbers, redact(l.redactor, r.Message)), l.maxMessageSize)
+	c.Fields = l.cleanFields(r.Fields)
+	for sub := range s.subs {
+		if sub.filter != nil && !sub.filter(c) {
+			continue
+		}
+		select {
+		case sub.ch <- c:
+		default:
+			reportDropped("subscription", 1)
+		}
+	}
+}
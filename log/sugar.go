@@ -0,0 +1,67 @@
+package log
+
+// badKey is the key given to values in keysAndValues that aren't preceded by a string key.
+const badKey = "!BADKEY"
+
+// kvFields converts the alternating keys and values taken by Infow and friends into fields. A Field
+// may appear in place of a key and value. Values without a string key are given the key "!BADKEY".
+func kvFields(keysAndValues []interface{}) []Field {
+	var fields []Field
+	for i := 0; i < len(keysAndValues); i++ {
+		switch k := keysAndValues[i].(type) {
+		case Field:
+			fields = append(fields, k)
+		case string:
+			if i+1 == len(keysAndValues) {
+				fields = append(fields, Field{Key: badKey, Value: k})
+				break
+			}
+			fields = append(fields, Field{Key: k, Value: keysAndValues[i+1]})
+			i++
+		default:
+			fields = append(fields, Field{Key: badKey, Value: k})
+		}
+	}
+	return fields
+}
+
+// Infow implements the Logger interface.
+func (l *logger) Infow(msg string, keysAndValues ...interface{}) {
+	l.logw(l.defaultVerbosity, InfoLevel, msg, groupFields(l.group, kvFields(keysAndValues)))
+}
+
+// Warningw implements the Logger interface.
+func (l *logger) Warningw(msg string, keysAndValues ...interface{}) {
+	l.logw(l.defaultVerbosity, WarningLevel, msg, groupFields(l.group, kvFields(keysAndValues)))
+}
+
+// Errorw implements the Logger interface.
+func (l *logger) Errorw(msg string, keysAndValues ...interface{}) {
+	l.logw(l.defaultVerbosity, ErrorLevel, msg, groupFields(l.group, kvFields(keysAndValues)))
+}
+
+// Fatalw implements the Logger interface.
+func (l *logger) Fatalw(msg string, keysAndValues ...interface{}) {
+	// Verbosity level is 0 because we always log fatal messages.
+	l.fatal(l.logw(0, FatalLevel, msg, groupFields(l.group, kvFields(keysAndValues))))
+}
+
+// Infow is a convenience method that calls defaultLogger.Infow(msg, keysAndValues...)
+func Infow(msg string, keysAndValues ...interface{}) {
+	defaultLogger.Infow(msg, keysAndValues...)
+}
+
+// Warningw is a convenience method that calls defaultLogger.Warningw(msg, keysAndValues...)
+func Warningw(msg string, keysAndValues ...interface{}) {
+	defaultLogger.Warningw(msg, keysAndValues...)
+}
+
+// Errorw is a convenience method that calls defaultLogger.Errorw(msg, keysAndValues...)
+func Errorw(msg string, keysAndValues ...interface{}) {
+	defaultLogger.Errorw(msg, keysAndValues...)
+}
+
+// Fatalw is a convenience method that calls defaultLogger.Fatalw(msg, keysAndValues...)
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	defaultLogger.Fatalw(msg, keysAndValues...)
+}
@@ -0,0 +1,108 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// SumoLogicOptions configures a SumoLogicWriter.
+type SumoLogicOptions struct {
+	// URL is the address of the hosted HTTP source, which includes its authentication token. It
+	// defaults to the SUMO_LOGIC_URL environment variable.
+	URL string
+	// Category, Host and Name override the source category, host and name configured for the HTTP
+	// source in Sumo Logic. Host defaults to the name reported by the kernel. They are not sent if
+	// empty.
+	Category string
+	Host     string
+	Name     string
+	// Fields are attached to every record as Sumo Logic fields, which can be searched without
+	// parsing the records. These are typically the fields bound to the logger with With.
+	Fields []Field
+	// Client posts the records. It defaults to a client with a timeout of DefaultHTTPTimeout.
+	Client *http.Client
+	// QueueSize is the number of records that can wait to be posted before records are dropped.
+	// It defaults to 4096.
+	QueueSize int
+	// MaxBatchBytes is the size of the records in a batch above which the batch is posted. It
+	// defaults to 1MB, the size recommended by Sumo Logic.
+	MaxBatchBytes int
+	// MaxLatency is the time after which a batch is posted regardless of its size. It defaults to
+	// 5 seconds.
+	MaxLatency time.Duration
+}
+
+// SumoLogicWriter posts records in batches to a Sumo Logic hosted HTTP source, one record per
+// line. Records are posted from a goroutine of their own, and are dropped rather than holding up
+// the logger when the source falls behind.
+type SumoLogicWriter struct {
+	*httpSink
+	opts   SumoLogicOptions
+	fields string
+}
+
+// NewSumoLogicWriter returns a SumoLogicWriter configured with opts. It fails if there is no
+// source URL.
+func NewSumoLogicWriter(opts SumoLogicOptions) (*SumoLogicWriter, error) {
+	if opts.URL == "" {
+		opts.URL = os.Getenv("SUMO_LOGIC_URL")
+	}
+	if opts.URL == "" {
+		return nil, errors.New("no Sumo Logic HTTP source URL")
+	}
+	if opts.Host == "" {
+		opts.Host, _ = os.Hostname()
+	}
+
+	w := &SumoLogicWriter{opts: opts, fields: sumoFields(opts.Fields)}
+	w.httpSink = newHTTPSink(opts.Client, w.request, opts.QueueSize, opts.MaxBatchBytes,
+		opts.MaxLatency)
+	return w, nil
+}
+
+// sumoFields returns the value of the X-Sumo-Fields header holding fields. Commas and equal signs,
+// which separate fields and their values in the header, are removed from keys and values.
+func sumoFields(fields []Field) string {
+	clean := strings.NewReplacer(",", "", "=", "")
+	var parts []string
+	for _, f := range fields {
+		parts = append(parts, clean.Replace(f.Key)+"="+clean.Replace(fmt.Sprint(jsonValue(f.Value))))
+	}
+	return strings.Join(parts, ",")
+}
+
+// request returns the gzip compressed request posting batch to the HTTP source.
+func (w *SumoLogicWriter) request(batch []sinkRecord) (*http.Request, error) {
+	var body bytes.Buffer
+	zw := gzip.NewWriter(&body)
+	for _, r := range batch {
+		zw.Write(r.p)
+		zw.Write([]byte{'\n'})
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.opts.URL, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Encoding", "gzip")
+	for header, value := range map[string]string{
+		"X-Sumo-Category": w.opts.Category,
+		"X-Sumo-Host":     w.opts.Host,
+		"X-Sumo-Name":     w.opts.Name,
+		"X-Sumo-Fields":   w.fields,
+	} {
+		if value != "" {
+			req.Header.Set(header, value)
+		}
+	}
+	return req, nil
+}
@@ -0,0 +1,72 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Telemetry receives measurements of the logging pipeline itself, so that its health can be
+// monitored in the same backend as the rest of the program. The adapter/otelmetric package
+// reports them as OpenTelemetry metrics. Methods are called synchronously from logging calls and
+// writer goroutines, so they must be quick and must not log.
+type Telemetry interface {
+	// Record is called for every record written, with the time it took to format and encode it.
+	Record(level Level, encode time.Duration)
+	// WriteError is called when a destination fails to write records.
+	WriteError(err error)
+	// Dropped is called when records are dropped, with the reason: "quota" for records over a
+	// quota, "queue" for records that didn't fit in the queue of an asynchronous or network
+	// writer, or "subscription" for records a subscriber fell too far behind to receive.
+	Dropped(reason string, n int64)
+}
+
+// telemetry holds the Telemetry set with SetTelemetry, if any.
+var telemetry atomic.Pointer[Telemetry]
+
+// SetTelemetry sets the Telemetry receiving measurements of all loggers and writers of the
+// package, replacing any previous one. A nil t stops reporting measurements.
+func SetTelemetry(t Telemetry) {
+	if t == nil {
+		telemetry.Store(nil)
+		return
+	}
+	telemetry.Store(&t)
+}
+
+// reportRecord reports a record of level that took encode to format and encode, if there is a
+// Telemetry.
+func reportRecord(level Level, encode time.Duration) {
+	if t := telemetry.Load(); t != nil {
+		(*t).Record(level, encode)
+	}
+}
+
+// reportWriteError reports the write error err, if there is a Telemetry.
+func reportWriteError(err error) {
+	if t := telemetry.Load(); t != nil {
+		(*t).WriteError(err)
+	}
+}
+
+// reportDropped reports n records dropped for reason, if there is a Telemetry.
+func reportDropped(reason string, n int64) {
+	if t := telemetry.Load(); t != nil {
+		(*t).Dropped(reason, n)
+	}
+}
+
+// queues maps the writers with queues that haven't been closed, such as AsyncWriters, to functions
+// returning the number of records in their queues.
+var queues sync.Map
+
+// QueueDepth returns the number of records waiting in the queues of all AsyncWriters and network
+// writers that haven't been closed.
+func QueueDepth() int {
+	n := 0
+	queues.Range(func(_, depth interface{}) bool {
+		n += depth.(func() int)()
+		return true
+	})
+	return n
+}
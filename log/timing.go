@@ -0,0 +1,56 @@
+package log
+
+import "time"
+
+// TimeTrack implements the Logger interface.
+func (l *logger) TimeTrack(start time.Time, operation string) {
+	l.logTiming(operation, time.Since(start), nil)
+}
+
+// Timed implements the Logger interface.
+func (l *logger) Timed(operation string, f func() error) error {
+	start := time.Now()
+	err := f()
+	l.logTiming(operation, time.Since(start), err)
+	return err
+}
+
+// SetTimingLevel implements the Logger interface.
+func (l *logger) SetTimingLevel(level Level) {
+	if level > ErrorLevel {
+		level = ErrorLevel
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.timingLevel = level
+}
+
+// logTiming logs the duration of an operation on behalf of TimeTrack or Timed. Failed operations
+// are logged as errors.
+func (l *logger) logTiming(operation string, elapsed time.Duration, err error) {
+	fields := []Field{{Key: "duration", Value: elapsed}}
+
+	l.mu.Lock()
+	level := l.timingLevel
+	l.mu.Unlock()
+	if err != nil {
+		level = ErrorLevel
+		fields = append(fields, Field{Key: "error", Value: err})
+	}
+
+	// Skip logwDepth, logTiming and the timing method, plus the package-level function for the
+	// default logger.
+	l.logwDepth(l.callerSkip, l.defaultVerbosity, level, operation, fields)
+}
+
+// TimeTrack is a convenience method that calls defaultLogger.TimeTrack(start, operation)
+func TimeTrack(start time.Time, operation string) {
+	defaultLogger.TimeTrack(start, operation)
+}
+
+// Timed is a convenience method that calls defaultLogger.Timed(operation, f)
+func Timed(operation string, f func() error) error {
+	return defaultLogger.Timed(operation, f)
+}
@@ -0,0 +1,40 @@
+package log
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// started holds the time Init was last called, or the time the package was initialized until
+// then, from which the uptime field of records is measured.
+var started atomic.Pointer[time.Time]
+
+func init() {
+	markStarted()
+}
+
+// markStarted restarts the uptime of records from now.
+func markStarted() {
+	now := time.Now()
+	started.Store(&now)
+}
+
+// SetUptime implements the Logger interface.
+func (l *logger) SetUptime(enabled bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.uptime = enabled
+}
+
+// SetUptime is a convenience method that calls defaultLogger.SetUptime(enabled)
+func SetUptime(enabled bool) {
+	defaultLogger.SetUptime(enabled)
+}
+
+// uptimeField returns the uptime field of a record written now.
+func uptimeField() Field {
+	// time.Since uses the monotonic clock reading of the start time, so wall clock adjustments
+	// don't affect it.
+	return Field{Key: "uptime", Value: time.Since(*started.Load())}
+}
@@ -0,0 +1,51 @@
+package log
+
+// Verbose logs info messages at a fixed verbosity level. Unlike VInfo, it lets callers check whether
+// the level is enabled before doing any work to produce a message:
+//
+//	if v := log.V(2); v.Enabled() {
+//		v.Infof("cache contents: %v", expensiveDump())
+//	}
+//
+// Verbose values are obtained from Logger.V or V.
+type Verbose struct {
+	l       *logger
+	level   int
+	enabled bool
+}
+
+// verboseSkip is the number of stack frames between logDepth or logfDepth and the caller of a
+// Verbose method.
+const verboseSkip = 2
+
+// V implements the Logger interface.
+func (l *logger) V(level int) Verbose {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return Verbose{l: l, level: level, enabled: level <= l.inheritedVerbosity()}
+}
+
+// V is a convenience method that calls defaultLogger.V(level)
+func V(level int) Verbose {
+	return defaultLogger.V(level)
+}
+
+// Enabled reports whether messages at v's verbosity level are written to the logs.
+func (v Verbose) Enabled() bool {
+	return v.enabled
+}
+
+// Info is like Logger.VInfo at v's verbosity level.
+func (v Verbose) Info(a ...interface{}) {
+	if v.enabled {
+		v.l.logDepth(verboseSkip, v.level, InfoLevel, a...)
+	}
+}
+
+// Infof is like Logger.VInfof at v's verbosity level.
+func (v Verbose) Infof(format string, a ...interface{}) {
+	if v.enabled {
+		v.l.logfDepth(verboseSkip, v.level, InfoLevel, format, a...)
+	}
+}
@@ -0,0 +1,54 @@
+package log
+
+import "time"
+
+// With implements the Logger interface.
+func (l *logger) With(fields ...Field) Logger {
+	c := l.Clone().(*logger)
+	c.fields = append(c.fields, groupFields(c.group, fields)...)
+	return c
+}
+
+// WithGroup implements the Logger interface.
+func (l *logger) WithGroup(name string) Logger {
+	c := l.Clone().(*logger)
+	if name != "" {
+		c.group += name + "."
+	}
+	return c
+}
+
+// WithTime implements the Logger interface.
+func (l *logger) WithTime(t time.Time) Logger {
+	c := l.Clone().(*logger)
+	c.eventTime = t
+	return c
+}
+
+// groupFields returns fields with their keys put in group, which is either empty or ends in a dot.
+// fields is returned as is if group is empty.
+func groupFields(group string, fields []Field) []Field {
+	if group == "" {
+		return fields
+	}
+	grouped := make([]Field, len(fields))
+	for i, f := range fields {
+		grouped[i] = Field{Key: group + f.Key, Value: f.Value}
+	}
+	return grouped
+}
+
+// With is a convenience method that calls defaultLogger.With(fields...)
+func With(fields ...Field) Logger {
+	return defaultLogger.With(fields...)
+}
+
+// WithGroup is a convenience method that calls defaultLogger.WithGroup(name)
+func WithGroup(name string) Logger {
+	return defaultLogger.WithGroup(name)
+}
+
+// WithTime is a convenience method that calls defaultLogger.WithTime(t)
+func WithTime(t time.Time) Logger {
+	return defaultLogger.WithTime(t)
+}